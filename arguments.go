@@ -2,35 +2,64 @@ package main
 
 import (
 	"log"
+	"os"
+	"regexp"
 
+	"github.com/acarl005/ls-go/lsgo"
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
 )
 
 // declare the struct that holds all the arguments
 type arguments struct {
-	paths     *[]string
-	version   *bool
-	all       *bool
-	bytes     *bool
-	mdate     *bool
-	owner     *bool
-	nogroup   *bool
-	perms     *bool
-	long      *bool
-	dirs      *bool
-	files     *bool
-	links     *bool
-	linkRel   *bool
-	sortSize  *bool
-	sortTime  *bool
-	sortKind  *bool
-	backwards *bool
-	stats     *bool
-	icons     *bool
-	nerdfont  *bool
-	recurse   *bool
-	find      *string
-	light     *bool
+	paths       *[]string
+	version     *bool
+	all         *bool
+	bytes       *bool
+	sizeStyle   *string
+	blockSize   *string
+	mdate       *bool
+	timeStyle   *string
+	timeColor   *string
+	timeField   *string
+	inode       *bool
+	blocks      *bool
+	linksCount  *bool
+	owner       *bool
+	nogroup     *bool
+	numericIDs  *bool
+	dirSize     *bool
+	sniff       *bool
+	perms       *bool
+	xattr       *bool
+	xattrValues *bool
+	acl         *bool
+	git         *bool
+	long        *bool
+	dirs        *bool
+	files       *bool
+	links       *bool
+	linkRel     *bool
+	sortSize    *bool
+	sortTime    *bool
+	sortKind    *bool
+	sort        *string
+	group       *string
+	columns     *string
+	header      *bool
+	backwards   *bool
+	stats       *bool
+	icons       *bool
+	nerdfont    *bool
+	recurse     *bool
+	tree        *bool
+	find        *string
+	gitIgnore   *bool
+	ignoreFiles *[]string
+	light       *bool
+	lsColors    *bool
+	color       *string
+	hyperlink   *bool
+	json        *bool
 }
 
 var args = arguments{
@@ -38,10 +67,25 @@ var args = arguments{
 	kingpin.Flag("version", "print version and exit").Short('v').Bool(),
 	kingpin.Flag("all", "show hidden files").Short('a').Bool(),
 	kingpin.Flag("bytes", "include size").Short('b').Bool(),
-	kingpin.Flag("mdate", "include modification date").Short('m').Bool(),
+	kingpin.Flag("size-style", "how to write sizes: iec (powers of 1024), si (powers of 1000), bytes, or blocks").Default("iec").Enum("iec", "si", "bytes", "blocks"),
+	kingpin.Flag("block-size", "the size of the blocks counted by --size-style=blocks, e.g. 512B or 4K").Default("1K").String(),
+	kingpin.Flag("mdate", "include modification date, or the time from --time-field").Short('m').Bool(),
+	kingpin.Flag("time-style", "how to write times: relative, iso, long-iso, full, or +LAYOUT with a Go time layout").PlaceHolder("STYLE").String(),
+	kingpin.Flag("time-color", "how to color times: hour (lighter around noon), age (brighter when recent), or plain").Default("hour").Enum("hour", "age", "plain"),
+	kingpin.Flag("time-field", "which time to show and sort by: modified, accessed, changed, or created").Default("modified").Enum("modified", "accessed", "changed", "created"),
+	kingpin.Flag("inode", "include the inode number of each item").Bool(),
+	kingpin.Flag("blocks", "include the number of blocks each item takes up on disk, and the total for each folder").Bool(),
+	kingpin.Flag("links-count", "include the number of hard links to each item").Bool(),
 	kingpin.Flag("owner", "include owner and group").Short('o').Bool(),
 	kingpin.Flag("nogroup", "hide group").Short('N').Bool(),
+	kingpin.Flag("numeric-uid-gid", "show the owner and group as numeric ids").Bool(),
+	kingpin.Flag("dir-size", "show the total size of everything inside each directory, like du").Short('D').Bool(),
+	kingpin.Flag("sniff", "read the start of files that aren't recognized by name to color them by their contents").Bool(),
 	kingpin.Flag("perms", "include permissions for owner, group, and other").Short('p').Bool(),
+	kingpin.Flag("xattr", "list the extended attributes of each item and their sizes beneath it").Bool(),
+	kingpin.Flag("xattr-values", "list the extended attributes with their values").Bool(),
+	kingpin.Flag("acl", "list the POSIX ACL entries of each item beneath it").Bool(),
+	kingpin.Flag("git", "include the git status of each item").Short('g').Bool(),
 	kingpin.Flag("long", "include size, date, owner, and permissions").Short('l').Bool(),
 	kingpin.Flag("dirs", "only show directories").Short('d').Bool(),
 	kingpin.Flag("files", "only show files").Short('f').Bool(),
@@ -50,15 +94,38 @@ var args = arguments{
 	kingpin.Flag("size", "sort items by size").Short('s').Bool(),
 	kingpin.Flag("time", "sort items by time").Short('t').Bool(),
 	kingpin.Flag("kind", "sort items by extension").Short('k').Bool(),
+	kingpin.Flag("sort", "sort by a list of keys: name, natural, version, size, time, or kind, with - to reverse one, e.g. kind,-time").Default("name").String(),
+	kingpin.Flag("group", "where to put directories: dirs-first, dirs-last, or none to sort them together with files").Default("dirs-first").Enum("dirs-first", "dirs-last", "none"),
+	kingpin.Flag("columns", "the long format columns to show, in order: inode, blocks, perms, links, owner, group, size, git, date, and name").PlaceHolder("COLUMNS").String(),
+	kingpin.Flag("header", "print the title of each column above the long format").Bool(),
 	kingpin.Flag("backwards", "reverse the sort order of --size, --time, or --kind").Short('B').Bool(),
 	kingpin.Flag("stats", "show statistics").Short('S').Bool(),
 	kingpin.Flag("icons", "show folder icon before dirs").Short('i').Bool(),
 	kingpin.Flag("nerd-font", "show nerd font glyphs before file names").Short('n').Bool(),
 	kingpin.Flag("recurse", "traverse all dirs recursively").Short('r').Bool(),
+	kingpin.Flag("tree", "traverse all dirs recursively and display them as a tree").Short('T').Bool(),
 	kingpin.Flag("find", "filter items with a regexp").Short('F').String(),
+	kingpin.Flag("git-ignore", "hide items ignored by git or by .lsgoignore files").Short('G').Bool(),
+	kingpin.Flag("ignore-file", "hide items matching the patterns in a gitignore-style file (repeatable)").PlaceHolder("PATH").ExistingFiles(),
 	kingpin.Flag("light", "output colors for light-bachground themes").Short('I').Bool(),
+	kingpin.Flag("ls-colors", "use the colors from the LS_COLORS environment variable when it's set (--no-ls-colors to ignore it)").Default("true").Bool(),
+	kingpin.Flag("color", "when to use colors: auto, always, or never").Default("auto").Enum("auto", "always", "never"),
+	kingpin.Flag("hyperlink", "make names clickable links to the files, when writing to a terminal").Bool(),
+	kingpin.Flag("json", "output each listing as a line of JSON instead of colored text").Short('j').Bool(),
 }
 
+// The compiled --find regexp, or nil if none was passed.
+var findRegexp *regexp.Regexp
+
+// The keys from --sort, after the keys from --kind, --size, and --time.
+var sortKeys []lsgo.SortKey
+
+// The columns from --columns, or nil to show the ones turned on by the other flags.
+var columns []lsgo.Column
+
+// The size in bytes from --block-size.
+var blockSize int64
+
 func argsPostParse() {
 	if *args.long {
 		args.bytes = &True
@@ -67,10 +134,155 @@ func argsPostParse() {
 		args.perms = &True
 		args.links = &True
 	}
+	if *args.tree {
+		args.recurse = &True
+	}
+	if *args.xattrValues {
+		args.xattr = &True
+	}
 	if *args.dirs && *args.files {
 		log.Fatal("--dirs and --files cannot both be set")
 	}
 	if *args.nerdfont && *args.icons {
 		log.Fatal("--nerd-font and --icons cannot both be set")
 	}
+	// these flags predate --sort and come before its keys, with kind first, then size, then time
+	for _, flag := range []struct {
+		isSet bool
+		field lsgo.SortField
+	}{{*args.sortKind, lsgo.SortByKind}, {*args.sortSize, lsgo.SortBySize}, {*args.sortTime, lsgo.SortByTime}} {
+		if flag.isSet {
+			sortKeys = append(sortKeys, lsgo.SortKey{Field: flag.field, Reverse: *args.backwards})
+		}
+	}
+	var err error
+	blockSize, err = lsgo.ParseSize(*args.blockSize)
+	if err != nil {
+		log.Fatal("invalid --block-size: ", err)
+	} else if blockSize <= 0 {
+		log.Fatal("--block-size must be positive")
+	}
+	if !lsgo.IsTimeStyle(*args.timeStyle) {
+		log.Fatal("invalid --time-style: ", *args.timeStyle)
+	}
+	keys, err := lsgo.ParseSortKeys(*args.sort)
+	if err != nil {
+		log.Fatal("invalid --sort: ", err)
+	}
+	sortKeys = append(sortKeys, keys...)
+	if len(*args.columns) > 0 {
+		columns, err = lsgo.ParseColumns(*args.columns)
+		if err != nil {
+			log.Fatal("invalid --columns: ", err)
+		}
+		// read whatever the columns need, like the flags that turn them on
+		for _, column := range columns {
+			switch column {
+			case lsgo.ColumnInode:
+				args.inode = &True
+			case lsgo.ColumnBlocks:
+				args.blocks = &True
+			case lsgo.ColumnPerms:
+				args.perms = &True
+			case lsgo.ColumnLinks:
+				args.linksCount = &True
+			case lsgo.ColumnOwner, lsgo.ColumnGroup:
+				args.owner = &True
+			case lsgo.ColumnGit:
+				args.git = &True
+			}
+		}
+	}
+	if len(*args.find) > 0 {
+		findRegexp, err = regexp.Compile(*args.find)
+		if err != nil {
+			log.Fatal("invalid --find regexp: ", err)
+		}
+	}
+}
+
+// The values of --group.
+var groupings = map[string]lsgo.Grouping{
+	"dirs-first": lsgo.GroupDirsFirst,
+	"dirs-last":  lsgo.GroupDirsLast,
+	"none":       lsgo.GroupNone,
+}
+
+// The values of --time-field.
+var timeFields = map[string]lsgo.TimeField{
+	"modified": lsgo.TimeModified,
+	"accessed": lsgo.TimeAccessed,
+	"changed":  lsgo.TimeChanged,
+	"created":  lsgo.TimeCreated,
+}
+
+// The values of --size-style.
+var sizeStyles = map[string]lsgo.SizeStyle{
+	"iec":    lsgo.SizeIEC,
+	"si":     lsgo.SizeSI,
+	"bytes":  lsgo.SizeBytes,
+	"blocks": lsgo.SizeBlocks,
+}
+
+// The values of --time-color.
+var timeColors = map[string]lsgo.TimeColor{
+	"hour":  lsgo.TimeColorHour,
+	"age":   lsgo.TimeColorAge,
+	"plain": lsgo.TimeColorPlain,
+}
+
+// listerOptions translates the flags that decide which items are listed and in what order.
+func listerOptions() lsgo.Options {
+	return lsgo.Options{
+		All:         *args.all,
+		DirsOnly:    *args.dirs,
+		FilesOnly:   *args.files,
+		Find:        findRegexp,
+		Sort:        sortKeys,
+		Group:       groupings[*args.group],
+		TimeField:   timeFields[*args.timeField],
+		LinkRel:     *args.linkRel,
+		Owners:      *args.owner || *args.perms || *args.json,
+		FileIDs:     *args.inode || *args.linksCount || *args.json,
+		NumericIDs:  *args.numericIDs,
+		Xattrs:      *args.perms || *args.json,
+		XattrValues: *args.xattr,
+		ACLs:        *args.acl || *args.json,
+		DirSize:     *args.dirSize,
+		Sniff:       *args.sniff,
+		Git:         *args.git,
+		GitIgnore:   *args.gitIgnore,
+		IgnoreFiles: *args.ignoreFiles,
+	}
+}
+
+// renderOptions translates the flags that decide how each item is printed.
+func renderOptions() lsgo.RenderOptions {
+	return lsgo.RenderOptions{
+		Inode:       *args.inode,
+		Blocks:      *args.blocks,
+		Perms:       *args.perms,
+		LinkCount:   *args.linksCount,
+		Owner:       *args.owner,
+		NoGroup:     *args.nogroup,
+		Bytes:       *args.bytes,
+		Mdate:       *args.mdate,
+		Git:         *args.git,
+		Columns:     columns,
+		Header:      *args.header,
+		Links:       *args.links,
+		Xattrs:      *args.xattr,
+		XattrValues: *args.xattrValues,
+		ACL:         *args.acl,
+		Icons:       *args.icons,
+		NerdFont:    *args.nerdfont,
+		JSON:        *args.json,
+		Grid:        lsgo.IsTerminal(os.Stdout),
+		Hyperlink:   *args.hyperlink && lsgo.IsTerminal(os.Stdout),
+		ErrOut:      stderr,
+		TimeStyle:   *args.timeStyle,
+		TimeColor:   timeColors[*args.timeColor],
+		SizeStyle:   sizeStyles[*args.sizeStyle],
+		BlockSize:   blockSize,
+	}
 }
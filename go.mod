@@ -3,17 +3,17 @@ module github.com/acarl005/ls-go
 go 1.19
 
 require (
-	github.com/acarl005/textcol v0.0.0
+	github.com/BurntSushi/toml v1.6.0
+	github.com/acarl005/stripansi v0.0.0-20180116102854-5a71ef0e047d
 	github.com/mattn/go-colorable v0.1.13
+	github.com/mattn/go-isatty v0.0.16
 	github.com/willf/pad v0.0.0-20200313202418-172aa767f2a4
 	golang.org/x/sys v0.0.0-20221010170243-090e33056c14
 	gopkg.in/alecthomas/kingpin.v2 v2.2.6
 )
 
 require (
-	github.com/acarl005/stripansi v0.0.0-20180116102854-5a71ef0e047d // indirect
 	github.com/alecthomas/template v0.0.0-20190718012654-fb15b899a751 // indirect
 	github.com/alecthomas/units v0.0.0-20211218093645-b94a6e3cc137 // indirect
-	github.com/mattn/go-isatty v0.0.16 // indirect
 	github.com/stretchr/testify v1.8.0 // indirect
 )
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/acarl005/ls-go/lsgo"
+)
+
+// The tests run the command in a child process, since the flags are parsed into globals, and run this
+// binary as ls-go when the variable is set.
+const runMainEnv = "LS_GO_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) != "" {
+		main()
+	}
+	os.Exit(m.Run())
+}
+
+// lsGo runs ls-go in the folder with the arguments, and returns what it printed and the exit code.
+func lsGo(t *testing.T, dir string, args ...string) (string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), runMainEnv+"=1", "XDG_CONFIG_HOME="+t.TempDir(), "PWD="+dir, "LS_COLORS=")
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return string(out), exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return string(out), 0
+}
+
+func TestExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "file"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args []string
+		code int
+	}{
+		{[]string{}, exitOK},
+		{[]string{"file", "sub"}, exitOK},
+		{[]string{"-r", "."}, exitOK},
+		{[]string{"missing"}, exitSerious},
+		{[]string{"file", "missing"}, exitSerious},
+		{[]string{"-j", "missing"}, exitSerious},
+	}
+	for _, test := range tests {
+		if _, code := lsGo(t, dir, test.args...); code != test.code {
+			t.Errorf("ls-go %q exited with %d, expected %d", test.args, code, test.code)
+		}
+	}
+}
+
+func TestExitCodeForUnreadableSubfolder(t *testing.T) {
+	if os.Getuid() == 0 {
+		t.Skip("root can read every folder")
+	}
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "secret")
+	if err := os.Mkdir(secret, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(secret, 0o755) })
+
+	if _, code := lsGo(t, dir, "-r"); code != exitMinor {
+		t.Errorf("ls-go -r exited with %d, expected %d", code, exitMinor)
+	}
+	if _, code := lsGo(t, dir, "secret"); code != exitSerious {
+		t.Errorf("ls-go secret exited with %d, expected %d", code, exitSerious)
+	}
+}
+
+func TestFileArgumentsFromAnotherFolder(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "file"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, code := lsGo(t, filepath.Join(dir, "sub"), "-j", "../file")
+	if code != exitOK {
+		t.Fatalf("ls-go exited with %d", code)
+	}
+	var listing lsgo.JSONListing
+	if err := json.Unmarshal([]byte(out), &listing); err != nil {
+		t.Fatal(err)
+	}
+	if len(listing.Entries) != 1 || listing.Entries[0].Path != filepath.Join(dir, "file") {
+		t.Errorf("expected the path of the file argument in %s", out)
+	}
+}
@@ -0,0 +1,127 @@
+package lsgo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+)
+
+// The extended attributes that hold POSIX ACLs on Linux.
+const (
+	aclAccessXattr  = "system.posix_acl_access"
+	aclDefaultXattr = "system.posix_acl_default"
+)
+
+// ACLEntry is one line of a POSIX access control list, like "user:alice:r-x" in the output of getfacl.
+type ACLEntry struct {
+	// "user", "group", "mask", or "other"
+	Tag string
+	// the name or id of the user or group, or "" for the owner, the owning group, the mask, and others
+	Qualifier string
+	// like "r-x"
+	Perms string
+	// whether this entry is inherited by new items in a folder rather than checked for the folder itself
+	Default bool
+}
+
+// String formats the entry like getfacl does.
+func (acl ACLEntry) String() string {
+	str := acl.Tag + ":" + acl.Qualifier + ":" + acl.Perms
+	if acl.Default {
+		return "default:" + str
+	}
+	return str
+}
+
+// The tags of the entries in the xattr, from linux/posix_acl_xattr.h.
+var aclTags = map[uint16]string{
+	0x01: "user",
+	0x02: "user",
+	0x04: "group",
+	0x08: "group",
+	0x10: "mask",
+	0x20: "other",
+}
+
+// readACL decodes the access and default ACLs of an entry whose xattrs have them. The user and group ids
+// are looked up like the owner and group.
+func (l *Lister) readACL(entry *Entry, absPath string) ([]ACLEntry, error) {
+	hasXattr := map[string]bool{}
+	for _, name := range entry.Xattrs {
+		hasXattr[name] = true
+	}
+	acl := []ACLEntry{}
+	// the access entries come first, like getfacl
+	for _, name := range []string{aclAccessXattr, aclDefaultXattr} {
+		if !hasXattr[name] {
+			continue
+		}
+		value, err := getXattr(absPath, name)
+		if err == nil {
+			var entries []ACLEntry
+			entries, err = l.parseACL(value, name == aclDefaultXattr)
+			acl = append(acl, entries...)
+		}
+		if err != nil {
+			return acl, &os.PathError{Op: "getxattr " + name, Path: absPath, Err: err}
+		}
+	}
+	return acl, nil
+}
+
+// parseACL decodes the little-endian format the kernel uses: a 4-byte version, then 8 bytes per entry with
+// the tag, the permission bits, and the id.
+func (l *Lister) parseACL(value []byte, isDefault bool) ([]ACLEntry, error) {
+	if len(value) < 4 || binary.LittleEndian.Uint32(value) != 2 || (len(value)-4)%8 != 0 {
+		return nil, fmt.Errorf("unknown ACL format")
+	}
+	entries := []ACLEntry{}
+	for offset := 4; offset < len(value); offset += 8 {
+		tagNum := binary.LittleEndian.Uint16(value[offset:])
+		perm := binary.LittleEndian.Uint16(value[offset+2:])
+		id := strconv.FormatUint(uint64(binary.LittleEndian.Uint32(value[offset+4:])), 10)
+
+		tag, isTag := aclTags[tagNum]
+		if !isTag {
+			return nil, fmt.Errorf("unknown ACL tag %#x", tagNum)
+		}
+		entry := ACLEntry{Tag: tag, Default: isDefault, Perms: "---"}
+		perms := []byte(entry.Perms)
+		for i, char := range "rwx" {
+			if perm&(4>>i) != 0 {
+				perms[i] = byte(char)
+			}
+		}
+		entry.Perms = string(perms)
+
+		// only the named users and groups have a qualifier
+		if tagNum == 0x02 || tagNum == 0x08 {
+			entry.Qualifier = l.aclName(tag, id)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// aclName looks up the name of a user or group in an ACL, or leaves the id with Options.NumericIDs.
+func (l *Lister) aclName(kind string, id string) string {
+	if l.opts.NumericIDs {
+		return id
+	}
+	return l.ids.lookup(kind, id, func() string {
+		if kind == "user" {
+			owner, err := user.LookupId(id)
+			if err != nil {
+				return id
+			}
+			return owner.Username
+		}
+		group, err := user.LookupGroupId(id)
+		if err != nil {
+			return id
+		}
+		return group.Name
+	})
+}
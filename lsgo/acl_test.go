@@ -0,0 +1,95 @@
+package lsgo
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// aclXattr encodes ACL entries of tag, perm, and id like the kernel does.
+func aclXattr(version uint32, entries ...[3]uint32) []byte {
+	value := binary.LittleEndian.AppendUint32(nil, version)
+	for _, entry := range entries {
+		value = binary.LittleEndian.AppendUint16(value, uint16(entry[0]))
+		value = binary.LittleEndian.AppendUint16(value, uint16(entry[1]))
+		value = binary.LittleEndian.AppendUint32(value, entry[2])
+	}
+	return value
+}
+
+func TestParseACL(t *testing.T) {
+	lister := NewLister(Options{NumericIDs: true})
+	undefinedID := uint32(0xffffffff)
+	tests := []struct {
+		name      string
+		value     []byte
+		isDefault bool
+		entries   []string
+	}{
+		{
+			"minimal",
+			aclXattr(2, [3]uint32{0x01, 6, undefinedID}, [3]uint32{0x04, 4, undefinedID}, [3]uint32{0x20, 4, undefinedID}),
+			false,
+			[]string{"user::rw-", "group::r--", "other::r--"},
+		},
+		{
+			"named entries",
+			aclXattr(2,
+				[3]uint32{0x01, 7, undefinedID},
+				[3]uint32{0x02, 5, 1000},
+				[3]uint32{0x04, 5, undefinedID},
+				[3]uint32{0x08, 3, 50},
+				[3]uint32{0x10, 7, undefinedID},
+				[3]uint32{0x20, 0, undefinedID},
+			),
+			false,
+			[]string{"user::rwx", "user:1000:r-x", "group::r-x", "group:50:-wx", "mask::rwx", "other::---"},
+		},
+		{
+			"default",
+			aclXattr(2, [3]uint32{0x01, 7, undefinedID}, [3]uint32{0x08, 1, 10}),
+			true,
+			[]string{"default:user::rwx", "default:group:10:--x"},
+		},
+		{
+			"empty",
+			aclXattr(2),
+			false,
+			[]string{},
+		},
+	}
+	for _, test := range tests {
+		acl, err := lister.parseACL(test.value, test.isDefault)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		entries := []string{}
+		for _, entry := range acl {
+			entries = append(entries, entry.String())
+		}
+		if !reflect.DeepEqual(entries, test.entries) {
+			t.Errorf("%s: got %q, want %q", test.name, entries, test.entries)
+		}
+	}
+}
+
+func TestParseACLErrors(t *testing.T) {
+	lister := NewLister(Options{NumericIDs: true})
+	tests := []struct {
+		name  string
+		value []byte
+		err   string
+	}{
+		{"empty", nil, "unknown ACL format"},
+		{"wrong version", aclXattr(1, [3]uint32{0x01, 7, 0}), "unknown ACL format"},
+		{"truncated entry", aclXattr(2, [3]uint32{0x01, 7, 0})[:10], "unknown ACL format"},
+		{"unknown tag", aclXattr(2, [3]uint32{0x40, 7, 0}), "unknown ACL tag 0x40"},
+	}
+	for _, test := range tests {
+		_, err := lister.parseACL(test.value, false)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%s: error = %v, want %q", test.name, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package lsgo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The longest xattr value that is shown before it's cut off.
+const maxXattrValueLen = 64
+
+// attrLines describes the extended attributes and the ACL of an entry, one per line, to be shown beneath it.
+func (r *Renderer) attrLines(entry *Entry) []string {
+	lines := []string{}
+	if r.opts.Xattrs {
+		colors := ConfigColor["xattr"]
+		for _, name := range entry.Xattrs {
+			// the ACL is shown in its own format
+			if r.opts.ACL && (name == aclAccessXattr || name == aclDefaultXattr) {
+				continue
+			}
+			line := colors["name"] + name
+			if value, hasValue := entry.XattrValues[name]; hasValue {
+				if r.opts.XattrValues {
+					line += colors["size"] + "=" + colors["value"] + xattrValueString(value)
+				} else {
+					line += colors["size"] + fmt.Sprintf(" (%d bytes)", len(value))
+				}
+			}
+			lines = append(lines, line+Reset)
+		}
+	}
+	if r.opts.ACL {
+		colors := ConfigColor["acl"]
+		for _, acl := range entry.ACL {
+			line := ""
+			if acl.Default {
+				line += colors["default"] + "default:"
+			}
+			line += colors["tag"] + acl.Tag + ":" + colors["qualifier"] + acl.Qualifier +
+				colors["tag"] + ":" + colors["perms"] + acl.Perms
+			lines = append(lines, line+Reset)
+		}
+	}
+	return lines
+}
+
+// xattrValueString quotes a value if it's text, or writes it in hex like `getfattr -e hex` if it's binary.
+// Long values are cut off.
+func xattrValueString(value []byte) string {
+	// C strings, like SELinux labels, end with a NUL
+	value = bytes.TrimRight(value, "\x00")
+	isText := utf8.Valid(value)
+	for _, char := range string(value) {
+		if !unicode.IsPrint(char) {
+			isText = false
+			break
+		}
+	}
+
+	if isText {
+		str := string(value)
+		if utf8.RuneCountInString(str) > maxXattrValueLen {
+			str = string([]rune(str)[:maxXattrValueLen]) + "…"
+		}
+		return strconv.Quote(str)
+	}
+	str := hex.EncodeToString(value)
+	if len(str) > maxXattrValueLen {
+		str = str[:maxXattrValueLen] + "…"
+	}
+	return "0x" + str
+}
+
+// attrIndent is the blank space beneath the long format columns, which the lines from attrLines start after.
+func attrIndent(item *displayItem) string {
+	return strings.Repeat(" ", visibleWidth(item.details))
+}
@@ -0,0 +1,29 @@
+package lsgo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestXattrValueString(t *testing.T) {
+	long := strings.Repeat("a", maxXattrValueLen+5)
+	tests := []struct {
+		value []byte
+		str   string
+	}{
+		{[]byte(""), `""`},
+		{[]byte("user_u:object_r:user_home_t:s0\x00"), `"user_u:object_r:user_home_t:s0"`},
+		{[]byte(`say "hi"`), `"say \"hi\""`},
+		{[]byte("héllo"), `"héllo"`},
+		{[]byte("line\nbreak"), "0x6c696e650a627265616b"},
+		{[]byte{0x02, 0x00, 0x00, 0x00, 0x01}, "0x0200000001"},
+		{[]byte{0xff, 0xfe}, "0xfffe"},
+		{[]byte(long), `"` + long[:maxXattrValueLen] + `…"`},
+		{[]byte(strings.Repeat("\x01", maxXattrValueLen)), "0x" + strings.Repeat("01", maxXattrValueLen/2) + "…"},
+	}
+	for _, test := range tests {
+		if str := xattrValueString(test.value); str != test.str {
+			t.Errorf("xattrValueString(%q) = %q, want %q", test.value, str, test.str)
+		}
+	}
+}
@@ -1,10 +1,13 @@
-package main
+package lsgo
 
 import (
 	"fmt"
 	"math"
+	"os"
 	"strconv"
 	"strings"
+
+	"github.com/mattn/go-isatty"
 )
 
 // see the 256-color codes
@@ -39,7 +42,7 @@ var lightThemeMap = map[int]int{
 }
 
 func applyTheme(index int) int {
-	if *args.light {
+	if lightTheme {
 		newIndex, hasNewIndex := lightThemeMap[index]
 		if hasNewIndex {
 			return newIndex
@@ -52,12 +55,18 @@ func applyTheme(index int) int {
 }
 
 func NamedFg(color Color) string {
+	if !colorEnabled {
+		return ""
+	}
 	index := applyTheme(int(color))
 	colored := []string{"\x1b[", strconv.Itoa(index + 30), "m"}
 	return strings.Join(colored, "")
 }
 
 func NamedBg(color Color) string {
+	if !colorEnabled {
+		return ""
+	}
 	index := applyTheme(int(color))
 	colored := []string{"\x1b[", strconv.Itoa(index + 40), "m"}
 	return strings.Join(colored, "")
@@ -65,12 +74,18 @@ func NamedBg(color Color) string {
 
 // Fg wraps an 8-bit foreground color code in the ANSI escape sequence
 func Fg(code int) string {
+	if !colorEnabled {
+		return ""
+	}
 	colored := []string{"\x1b[38;5;", strconv.Itoa(code), "m"}
 	return strings.Join(colored, "")
 }
 
 // Bg wraps an 8-bit background color code in the ANSI escape sequence
 func Bg(code int) string {
+	if !colorEnabled {
+		return ""
+	}
 	colored := []string{"\x1b[48;5;", strconv.Itoa(code), "m"}
 	return strings.Join(colored, "")
 }
@@ -82,7 +97,7 @@ func avg(a float64, b float64) int {
 
 // Rgb2code converts RGB values (up to 5) to an 8-bit color code
 func Rgb2code(r int, g int, b int, theme bool) int {
-	if theme && *args.light {
+	if theme && lightTheme {
 		r_, g_, b_ := float64(r), float64(g), float64(b)
 		r = 5 - avg(g_, b_)
 		g = 5 - avg(r_, b_)
@@ -97,7 +112,7 @@ func Rgb2code(r int, g int, b int, theme bool) int {
 
 // Gray2code converts a scalar of "grayness" to an 8-bit color code
 func Gray2code(lightness int) int {
-	if *args.light {
+	if lightTheme {
 		lightness = 23 - lightness
 	}
 	code := lightness + 232
@@ -137,12 +152,46 @@ func BgGray(lightness int) string {
 	return Bg(Gray2code(lightness))
 }
 
-const (
+var (
 	// Reset undoes ANSI color codes
 	Reset = "\x1b[0m"
 	Bold  = "\x1b[1m"
+	// colorEnabled is turned off by --color=never, or when the output is not a terminal
+	colorEnabled = true
+	// lightTheme adjusts the colors for light-background terminals, and is set by GenerateColors
+	lightTheme = false
 )
 
+// UseColor decides whether to emit ANSI codes based on the --color mode. In "auto" mode, the NO_COLOR and
+// CLICOLOR_FORCE conventions are respected before checking whether stdout is a terminal.
+func UseColor(mode string) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true
+	}
+	return IsTerminal(os.Stdout)
+}
+
+// IsTerminal checks whether a file is an interactive terminal, including the Cygwin ones on Windows.
+func IsTerminal(file *os.File) bool {
+	return isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd())
+}
+
+// DisableColor makes all the color functions produce empty strings. It must be called before GenerateColors.
+func DisableColor() {
+	colorEnabled = false
+	Reset = ""
+	Bold = ""
+}
+
 var (
 	// FileColor is a mapping of filetypes to colors
 	FileColor map[string]FileColorConfig
@@ -330,7 +379,10 @@ type FileColorConfig struct {
 	themeSwitch bool
 }
 
-func generateColors() {
+// GenerateColors builds the default color maps, using the variants for light-background themes if asked.
+// It must be called before anything is rendered.
+func GenerateColors(light bool) {
+	lightTheme = light
 	FileColor = map[string]FileColorConfig{
 		"as":      {FgRGB(5, 0, 0), FgRGB(3, 0, 0), true},
 		"asm":     {FgRGBT(5, 4, 3), FgRGBT(5, 3, 1), false},
@@ -403,6 +455,8 @@ func generateColors() {
 		"M": FgRGBT(1, 4, 5),
 		"G": FgRGBT(2, 5, 5),
 		"T": FgRGBT(3, 5, 5),
+		"P": FgRGBT(4, 5, 5),
+		"E": FgRGBT(5, 5, 5),
 	}
 
 	ConfigColor = map[string]map[string]string{
@@ -435,6 +489,48 @@ func generateColors() {
 		"pipe": {
 			"name": Bold + BgRGBT(2, 1, 0) + FgGray(23),
 		},
+		"git": {
+			"clean":      FgGray(6),
+			"new":        NamedFg(BrightGreen),
+			"modified":   NamedFg(BrightYellow),
+			"deleted":    NamedFg(BrightRed),
+			"conflicted": Bold + NamedFg(BrightMagenta),
+			"ignored":    FgGray(10),
+		},
+		"tree": {
+			"connector": FgGray(8),
+		},
+		// the inode, allocated blocks, and hard link columns, and the total blocks of a folder
+		"counts": {
+			"inode":  FgRGBT(1, 2, 4),
+			"blocks": FgRGBT(1, 3, 3),
+			"links":  FgGray(14),
+			"total":  FgGray(15),
+		},
+		// the extended attributes and ACL entries shown beneath each name
+		"xattr": {
+			"name":  FgRGBT(2, 3, 4),
+			"size":  FgGray(10),
+			"value": FgGray(16),
+		},
+		"acl": {
+			"default":   FgGray(10),
+			"tag":       FgRGBT(0, 3, 4),
+			"qualifier": FgRGBT(3, 4, 5),
+			"perms":     FgGray(18),
+		},
+		// timestamps with --time-color=age, from the most recent to the oldest
+		"age": {
+			"now":   Bold + FgRGBT(5, 5, 1),
+			"today": FgRGBT(4, 4, 2),
+			"week":  FgRGBT(2, 4, 3),
+			"month": FgRGBT(1, 2, 3),
+			"older": FgGray(10),
+		},
+		// the titles of the columns with --header
+		"header": {
+			"title": Bold + FgGray(15),
+		},
 		"stats": {
 			"text":   BgGray(2) + FgGray(15),
 			"number": FgRGBT(0, 2, 3),
@@ -458,5 +554,14 @@ func generateColors() {
 		"other": {
 			"_default": FgGray(15),
 		},
+		// bits that deserve a closer look when auditing a system
+		"special": {
+			"setuid":        Bold + NamedBg(Red) + NamedFg(BrightWhite),
+			"setgid":        Bold + NamedBg(Yellow) + NamedFg(Black),
+			"sticky":        Bold + NamedFg(BrightBlue),
+			"worldWritable": Bold + NamedFg(BrightRed),
+			"acl":           NamedFg(BrightCyan),
+			"xattr":         FgGray(12),
+		},
 	}
 }
@@ -0,0 +1,252 @@
+package lsgo
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Column is one of the fields of the long format.
+type Column int
+
+const (
+	ColumnInode Column = iota
+	ColumnBlocks
+	ColumnPerms
+	ColumnLinks
+	ColumnOwner
+	ColumnGroup
+	ColumnSize
+	ColumnGit
+	ColumnDate
+	ColumnName
+)
+
+// The names of the columns for ParseColumns.
+var columnNames = map[string]Column{
+	"inode":  ColumnInode,
+	"blocks": ColumnBlocks,
+	"perms":  ColumnPerms,
+	"links":  ColumnLinks,
+	"owner":  ColumnOwner,
+	"group":  ColumnGroup,
+	"size":   ColumnSize,
+	"git":    ColumnGit,
+	"date":   ColumnDate,
+	"name":   ColumnName,
+}
+
+// ParseColumns reads a comma-separated list of columns, like "perms,size,name", in the order they are
+// shown.
+func ParseColumns(spec string) ([]Column, error) {
+	columns := []Column{}
+	seen := map[Column]bool{}
+	for _, name := range strings.Split(spec, ",") {
+		column, isColumn := columnNames[strings.TrimSpace(name)]
+		if !isColumn {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("%q is listed more than once", name)
+		}
+		seen[column] = true
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// columnSpec describes how to show a column. The text of a cell can have colors of its own.
+type columnSpec struct {
+	title      string
+	alignRight bool
+	minWidth   int
+	cell       func(r *Renderer, entry *Entry) (color string, text string)
+}
+
+var columnSpecs = map[Column]columnSpec{
+	ColumnInode: {"Inode", true, 0, func(r *Renderer, entry *Entry) (string, string) {
+		return ConfigColor["counts"]["inode"], strconv.FormatUint(entry.Inode, 10)
+	}},
+	ColumnBlocks: {"Blocks", true, 0, func(r *Renderer, entry *Entry) (string, string) {
+		return ConfigColor["counts"]["blocks"], strconv.FormatInt(r.blockCount(entry.OwnDiskSize), 10)
+	}},
+	ColumnPerms: {"Permissions", false, 0, func(r *Renderer, entry *Entry) (string, string) {
+		ownerColor, groupColor := getOwnerAndGroupColors(entry.Owner, entry.Group)
+		return "", permString(entry, ownerColor, groupColor)
+	}},
+	ColumnLinks: {"Links", true, 0, func(r *Renderer, entry *Entry) (string, string) {
+		return ConfigColor["counts"]["links"], strconv.FormatUint(entry.Links, 10)
+	}},
+	ColumnOwner: {"Owner", false, 0, func(r *Renderer, entry *Entry) (string, string) {
+		ownerColor, _ := getOwnerAndGroupColors(entry.Owner, entry.Group)
+		return ownerColor, entry.Owner
+	}},
+	ColumnGroup: {"Group", false, 0, func(r *Renderer, entry *Entry) (string, string) {
+		_, groupColor := getOwnerAndGroupColors(entry.Owner, entry.Group)
+		return groupColor, entry.Group
+	}},
+	ColumnSize: {"Size", true, unitSizeWidth, func(r *Renderer, entry *Entry) (string, string) {
+		if entry.Info.Mode()&os.ModeDevice != 0 {
+			return "", deviceString(entry.Major, entry.Minor)
+		}
+		return r.sizeColor(entry), r.sizeText(entry)
+	}},
+	ColumnGit: {"Git", false, 0, func(r *Renderer, entry *Entry) (string, string) {
+		return "", gitStatusString(entry.GitStatus)
+	}},
+	ColumnDate: {"Date", false, 0, func(r *Renderer, entry *Entry) (string, string) {
+		return "", r.timeString(entry.Time)
+	}},
+	ColumnName: {"Name", false, 0, func(r *Renderer, entry *Entry) (string, string) {
+		label := r.nameString(entry)
+		if r.opts.Links && entry.Info.Mode()&os.ModeSymlink != 0 {
+			label += r.linkString(entry)
+		}
+		return "", label
+	}},
+}
+
+// columns returns the columns to show, either the ones that were asked for, or the ones turned on by the
+// other options in the usual order.
+func (r *Renderer) columns() []Column {
+	if r.opts.Columns != nil {
+		return r.opts.Columns
+	}
+	columns := []Column{}
+	for _, column := range []struct {
+		isSet  bool
+		column Column
+	}{
+		{r.opts.Inode, ColumnInode},
+		{r.opts.Blocks, ColumnBlocks},
+		{r.opts.Perms, ColumnPerms},
+		{r.opts.LinkCount, ColumnLinks},
+		{r.opts.Owner, ColumnOwner},
+		{r.opts.Owner && !r.opts.NoGroup, ColumnGroup},
+		{r.opts.Bytes, ColumnSize},
+		{r.opts.Git, ColumnGit},
+		{r.opts.Mdate, ColumnDate},
+		{true, ColumnName},
+	} {
+		if column.isSet {
+			columns = append(columns, column.column)
+		}
+	}
+	return columns
+}
+
+// hasColumn checks whether a column is shown.
+func (r *Renderer) hasColumn(column Column) bool {
+	for _, shown := range r.columns() {
+		if shown == column {
+			return true
+		}
+	}
+	return false
+}
+
+// A cell of the table, before it's padded.
+type tableCell struct {
+	color string
+	text  string
+	width int
+}
+
+// layoutTable fills in the columns of each item, padding every cell to line up with the widest one in its
+// column. Everything before the name goes in the details, so that the tree can be drawn in between. The
+// header is "" unless RenderOptions.Header is set and the long format is shown.
+func (r *Renderer) layoutTable(items []*displayItem) string {
+	columns := r.columns()
+	cells := make([][]tableCell, len(items))
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		spec := columnSpecs[column]
+		widths[i] = spec.minWidth
+		if r.opts.Header {
+			widths[i] = max(widths[i], len(spec.title))
+		}
+	}
+	for row, item := range items {
+		cells[row] = make([]tableCell, len(columns))
+		for i, column := range columns {
+			color, text := columnSpecs[column].cell(r, item.Entry)
+			width := visibleWidth(text)
+			cells[row][i] = tableCell{color, text, width}
+			widths[i] = max(widths[i], width)
+		}
+	}
+
+	// a column that's blank for every item, like the git status outside of a repo, is left out, so the last
+	// column that isn't is the one that doesn't get padded
+	lastShown := -1
+	for i := range columns {
+		if widths[i] != 0 {
+			lastShown = i
+		}
+	}
+
+	for row, item := range items {
+		afterName := false
+		for i, column := range columns {
+			if widths[i] == 0 {
+				continue
+			}
+			cell := r.padCell(cells[row][i], columnSpecs[column], widths[i], i == lastShown)
+			if column == ColumnName {
+				afterName = true
+				item.label = cell
+			} else if afterName {
+				item.after += cell
+			} else {
+				item.details += cell
+			}
+		}
+	}
+
+	if !r.opts.Header || len(items) == 0 || !r.isLongFormat() {
+		return ""
+	}
+	header := ""
+	headerColor := ConfigColor["header"]["title"]
+	for i, column := range columns {
+		if widths[i] == 0 {
+			continue
+		}
+		spec := columnSpecs[column]
+		title := spec.title
+		if column == ColumnName {
+			// line up with the name, after the icon
+			title = strings.Repeat(" ", r.iconWidth()) + title
+		}
+		header += r.padCell(tableCell{headerColor, title, len(title)}, spec, widths[i], i == lastShown)
+	}
+	return header
+}
+
+// iconWidth is how far the names are indented by the icon in front of them, or the space in its place. The
+// emoji icons take up 2 cells of the terminal, and the nerd font glyphs 1.
+func (r *Renderer) iconWidth() int {
+	if r.opts.NerdFont {
+		return 2
+	} else if r.opts.Icons {
+		return 3
+	}
+	return 1
+}
+
+// padCell pads the text of a cell to the width of its column, followed by a space. The last column isn't
+// padded at all, so lines don't end with spaces.
+func (r *Renderer) padCell(cell tableCell, spec columnSpec, width int, isLast bool) string {
+	if isLast {
+		if cell.color == "" {
+			return cell.text
+		}
+		return cell.color + cell.text + Reset
+	}
+	padding := strings.Repeat(" ", width-cell.width)
+	if spec.alignRight {
+		return cell.color + padding + cell.text + " " + Reset
+	}
+	return cell.color + cell.text + padding + " " + Reset
+}
@@ -0,0 +1,104 @@
+package lsgo
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseColumns(t *testing.T) {
+	tests := []struct {
+		spec    string
+		columns []Column
+	}{
+		{"name", []Column{ColumnName}},
+		{"perms,size,name", []Column{ColumnPerms, ColumnSize, ColumnName}},
+		{"name, git ,date", []Column{ColumnName, ColumnGit, ColumnDate}},
+		{
+			"inode,blocks,perms,links,owner,group,size,git,date,name",
+			[]Column{ColumnInode, ColumnBlocks, ColumnPerms, ColumnLinks, ColumnOwner, ColumnGroup, ColumnSize,
+				ColumnGit, ColumnDate, ColumnName},
+		},
+	}
+	for _, test := range tests {
+		columns, err := ParseColumns(test.spec)
+		if err != nil {
+			t.Errorf("ParseColumns(%q) unexpected error: %s", test.spec, err)
+		} else if !reflect.DeepEqual(columns, test.columns) {
+			t.Errorf("ParseColumns(%q) = %v, want %v", test.spec, columns, test.columns)
+		}
+	}
+
+	errTests := []struct {
+		spec string
+		err  string
+	}{
+		{"", `unknown column ""`},
+		{"name,mode", `unknown column "mode"`},
+		{"size,name,size", `"size" is listed more than once`},
+	}
+	for _, test := range errTests {
+		if _, err := ParseColumns(test.spec); err == nil || err.Error() != test.err {
+			t.Errorf("ParseColumns(%q) error = %v, want %q", test.spec, err, test.err)
+		}
+	}
+}
+
+func TestPadCell(t *testing.T) {
+	r := NewRenderer(nil, RenderOptions{})
+	left := columnSpec{alignRight: false}
+	right := columnSpec{alignRight: true}
+	tests := []struct {
+		text   string
+		spec   columnSpec
+		width  int
+		isLast bool
+		padded string
+	}{
+		{"ab", left, 4, false, "ab   " + Reset},
+		{"ab", right, 4, false, "  ab " + Reset},
+		{"ab", left, 4, true, "ab"},
+		{"abcd", right, 4, false, "abcd " + Reset},
+	}
+	for _, test := range tests {
+		cell := tableCell{"", test.text, len(test.text)}
+		if padded := r.padCell(cell, test.spec, test.width, test.isLast); padded != test.padded {
+			t.Errorf("padCell(%q, width %d, last %v) = %q, want %q", test.text, test.width, test.isLast, padded,
+				test.padded)
+		}
+	}
+}
+
+func TestTableLayout(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "small"), "x")
+	writeTestFile(t, filepath.Join(dir, "big.txt"), strings.Repeat("x", 123456))
+	writeTestFile(t, filepath.Join(dir, "sub", "file"), "")
+	listing := listTestDir(t, Options{}, dir)
+
+	for _, opts := range []RenderOptions{
+		{Perms: true, Bytes: true, Header: true},
+		{Columns: []Column{ColumnSize, ColumnName, ColumnPerms}, Header: true},
+	} {
+		lines := strings.Split(strings.TrimSuffix(renderTestListing(t, opts, listing), "\n"), "\n")
+		if len(lines) != 4 {
+			t.Fatalf("%v: expected a header and 3 rows, got %q", opts.Columns, lines)
+		}
+		header := lines[0]
+		// the names line up with their title, and the sizes are right-aligned under theirs
+		nameAt := strings.Index(header, "Name")
+		sizeEnd := strings.Index(header, "Size") + len("Size")
+		for i, name := range []string{"sub", "big.txt", "small"} {
+			row := lines[i+1]
+			if at := strings.Index(row, name); at != nameAt {
+				t.Errorf("%v: %s is at %d, but its title is at %d:\n%s\n%s", opts.Columns, name, at, nameAt, header,
+					row)
+			}
+			if sizeEnd > len(row) || row[sizeEnd-1] == ' ' || row[sizeEnd] != ' ' {
+				t.Errorf("%v: the size of %s isn't right-aligned at %d:\n%s\n%s", opts.Columns, name, sizeEnd,
+					header, row)
+			}
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package lsgo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ConfigPath finds the user's config file, following the XDG base directory spec.
+func ConfigPath() string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(configHome, "ls-go", "config.toml")
+}
+
+// LoadConfig reads the optional config file and merges it on top of the built-in colors and icons. It must
+// be called after GenerateColors. A missing file is not an error.
+func LoadConfig(configFile string) error {
+	file, err := os.Open(configFile)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	config := map[string]interface{}{}
+	if _, err := toml.NewDecoder(file).Decode(&config); err != nil {
+		return fmt.Errorf("%s: %s", configFile, err)
+	}
+	if err := applyConfig(config); err != nil {
+		return fmt.Errorf("%s: %s", configFile, err)
+	}
+	return nil
+}
+
+// configSections are the sections of the config file and the maps they're merged into, in the order they're
+// applied. [file-aliases] comes after [files], so an extension in both ends up as an alias.
+//
+//	[files]         file extension -> color, or [main color, accent color]    (FileColor)
+//	[file-aliases]  file extension -> another key in [files]                  (FileAliases)
+//	[sizes]         size unit, e.g. "K" -> color                             (SizeColor)
+//	[size-scale]    size unit -> smallest size with its color, e.g. "100K"   (SizeScale)
+//	[colors.<name>] color name -> color, e.g. [colors.dir] name = "..."      (ConfigColor)
+//	[perms.<name>]  user, group, or "other" -> color                         (PermsColor)
+//	[icons]         file extension or full file name -> nerd font glyph      (icons)
+//	[icon-aliases]  file extension or full file name -> another key in icons (aliases)
+//	[folders]       folder name -> nerd font glyph                           (folders)
+var configSections = []struct {
+	name  string
+	apply func(values map[string]interface{}) error
+}{
+	{"files", applyFileColors},
+	{"file-aliases", applyFileAliases},
+	{"sizes", func(values map[string]interface{}) error { return applyStrings(SizeColor, values, true) }},
+	{"size-scale", applySizeScale},
+	{"colors", func(values map[string]interface{}) error { return applyColorTables("colors", ConfigColor, values) }},
+	{"perms", func(values map[string]interface{}) error { return applyColorTables("perms", PermsColor, values) }},
+	{"icons", func(values map[string]interface{}) error { return applyStrings(icons, values, false) }},
+	{"icon-aliases", func(values map[string]interface{}) error { return applyStrings(aliases, values, false) }},
+	{"folders", func(values map[string]interface{}) error { return applyStrings(folders, values, false) }},
+}
+
+// applyConfig merges each section of the decoded config file into the map it corresponds to.
+func applyConfig(config map[string]interface{}) error {
+	for _, name := range sortedKeys(config) {
+		if _, isTable := config[name].(map[string]interface{}); !isTable {
+			return fmt.Errorf("keys must be inside a section, e.g. [files]")
+		}
+		known := false
+		for _, section := range configSections {
+			known = known || section.name == name
+		}
+		if !known {
+			return fmt.Errorf("unknown section [%s]", name)
+		}
+	}
+	for _, section := range configSections {
+		values, _ := config[section.name].(map[string]interface{})
+		if err := section.apply(values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyColorTables merges the tables nested in a section, e.g. [colors.dir], into the color maps by name.
+func applyColorTables(section string, dest map[string]map[string]string, values map[string]interface{}) error {
+	for _, name := range sortedKeys(values) {
+		table, isTable := values[name].(map[string]interface{})
+		if !isTable {
+			return fmt.Errorf("%s must be a table, e.g. [%s.%s]", name, section, name)
+		}
+		if dest[name] == nil {
+			dest[name] = map[string]string{}
+		}
+		if err := applyStrings(dest[name], table, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortedKeys orders the keys of a table, so that keys which end up the same, like "GO" and "go" in [files],
+// are applied in the same order every time.
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func applyStrings(dest map[string]string, values map[string]interface{}, isColor bool) error {
+	for _, key := range sortedKeys(values) {
+		str, isString := values[key].(string)
+		if !isString {
+			return fmt.Errorf("%s must be a string", key)
+		}
+		if isColor {
+			color, err := parseColor(str)
+			if err != nil {
+				return fmt.Errorf("%s: %s", key, err)
+			}
+			str = color
+		}
+		dest[key] = str
+	}
+	return nil
+}
+
+func applySizeScale(values map[string]interface{}) error {
+	for _, key := range sortedKeys(values) {
+		if _, isUnit := SizeScale[key]; !isUnit {
+			return fmt.Errorf("%s is not a size unit", key)
+		}
+		str, isString := values[key].(string)
+		if !isString {
+			return fmt.Errorf("%s must be a string", key)
+		}
+		size, err := ParseSize(str)
+		if err != nil {
+			return fmt.Errorf("%s: %s", key, err)
+		}
+		SizeScale[key] = size
+	}
+	return nil
+}
+
+func applyFileColors(values map[string]interface{}) error {
+	for _, key := range sortedKeys(values) {
+		var specs []string
+		switch value := values[key].(type) {
+		case string:
+			specs = []string{value, value}
+		case []interface{}:
+			for _, spec := range value {
+				if str, isString := spec.(string); isString {
+					specs = append(specs, str)
+				}
+			}
+			if len(specs) != len(value) {
+				specs = nil
+			}
+		}
+		if len(specs) != 2 {
+			return fmt.Errorf("%s must be a color or an array of 2 colors", key)
+		}
+		light, err := parseColor(specs[0])
+		if err != nil {
+			return fmt.Errorf("%s: %s", key, err)
+		}
+		dark, err := parseColor(specs[1])
+		if err != nil {
+			return fmt.Errorf("%s: %s", key, err)
+		}
+		setFileColor(strings.ToLower(key), FileColorConfig{light, dark, false})
+	}
+	return nil
+}
+
+// applyFileAliases lowercases the extensions, and the keys of [files] they point to, since that's how they
+// are looked up.
+func applyFileAliases(values map[string]interface{}) error {
+	for _, key := range sortedKeys(values) {
+		str, isString := values[key].(string)
+		if !isString {
+			return fmt.Errorf("%s must be a string", key)
+		}
+		FileAliases[strings.ToLower(key)] = strings.ToLower(str)
+	}
+	return nil
+}
+
+var namedColors = map[string]Color{
+	"black":          Black,
+	"red":            Red,
+	"green":          Green,
+	"yellow":         Yellow,
+	"blue":           Blue,
+	"magenta":        Magenta,
+	"cyan":           Cyan,
+	"white":          White,
+	"bright-black":   BrightBlack,
+	"bright-red":     BrightRed,
+	"bright-green":   BrightGreen,
+	"bright-yellow":  BrightYellow,
+	"bright-blue":    BrightBlue,
+	"bright-magenta": BrightMagenta,
+	"bright-cyan":    BrightCyan,
+	"bright-white":   BrightWhite,
+}
+
+// parseColor converts a color description from the config file into ANSI escape codes. The description is
+// a space-separated list of any of these, where a "bg:" prefix makes it a background color:
+//
+//	bold              bold text
+//	red, bright-red   one of the 16 named colors
+//	rgb(5,0,2)        an xterm-256 color cube value, each component up to 5
+//	rgbt(5,0,2)       same as rgb, but adjusted for the --light theme
+//	gray(15)          a grayscale value up to 23
+//	208               a raw xterm-256 color code
+func parseColor(spec string) (string, error) {
+	colored := []string{}
+	for _, token := range colorTokens(strings.ToLower(spec)) {
+		if token == "bold" {
+			colored = append(colored, Bold)
+			continue
+		}
+		isBg := strings.HasPrefix(token, "bg:")
+		token = strings.TrimPrefix(token, "bg:")
+
+		var code string
+		if named, isNamed := namedColors[token]; isNamed {
+			if isBg {
+				code = NamedBg(named)
+			} else {
+				code = NamedFg(named)
+			}
+			colored = append(colored, code)
+			continue
+		}
+
+		var colorCode int
+		var err error
+		if open := strings.Index(token, "("); open > 0 && strings.HasSuffix(token, ")") {
+			colorCode, err = colorFunc(token[:open], strings.Split(token[open+1:len(token)-1], ","))
+		} else {
+			colorCode, err = strconv.Atoi(token)
+			if err == nil && (colorCode < 0 || colorCode > 255) {
+				err = fmt.Errorf("color code %d out of range", colorCode)
+			}
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid color %q: %s", token, err)
+		}
+		if isBg {
+			colored = append(colored, Bg(colorCode))
+		} else {
+			colored = append(colored, Fg(colorCode))
+		}
+	}
+	return strings.Join(colored, ""), nil
+}
+
+// colorTokens splits a color spec on whitespace, except inside parentheses, so "bg:rgb(5, 0, 2)" stays one
+// token.
+func colorTokens(spec string) []string {
+	tokens := []string{}
+	token := strings.Builder{}
+	depth := 0
+	for _, char := range spec {
+		switch {
+		case char == '(':
+			depth++
+		case char == ')' && depth > 0:
+			depth--
+		case unicode.IsSpace(char):
+			if depth == 0 && token.Len() > 0 {
+				tokens = append(tokens, token.String())
+				token.Reset()
+			}
+			continue
+		}
+		token.WriteRune(char)
+	}
+	if token.Len() > 0 {
+		tokens = append(tokens, token.String())
+	}
+	return tokens
+}
+
+func colorFunc(name string, argStrs []string) (code int, err error) {
+	nums := []int{}
+	for _, argStr := range argStrs {
+		num, err := strconv.Atoi(strings.TrimSpace(argStr))
+		if err != nil {
+			return 0, err
+		}
+		nums = append(nums, num)
+	}
+
+	// the code generators panic on invalid values, which we want to report as a normal error
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	switch {
+	case (name == "rgb" || name == "rgbt") && len(nums) == 3:
+		for _, num := range nums {
+			if num < 0 || num > 5 {
+				return 0, fmt.Errorf("RGB values must be between 0 and 5")
+			}
+		}
+		return Rgb2code(nums[0], nums[1], nums[2], name == "rgbt"), nil
+	case name == "gray" && len(nums) == 1:
+		return Gray2code(nums[0]), nil
+	}
+	return 0, fmt.Errorf("unknown color function")
+}
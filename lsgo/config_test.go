@@ -0,0 +1,249 @@
+package lsgo
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	GenerateColors(false)
+	tests := []struct {
+		spec  string
+		color string
+	}{
+		{"", ""},
+		{"bold", Bold},
+		{"red", NamedFg(Red)},
+		{"Bright-Red", NamedFg(BrightRed)},
+		{"bg:blue", NamedBg(Blue)},
+		{"208", Fg(208)},
+		{"bg:0", Bg(0)},
+		{"rgb(5,0,2)", FgRGB(5, 0, 2)},
+		{"rgb(5, 0, 2)", FgRGB(5, 0, 2)},
+		{"bg:rgb( 0 , 0 , 2 )", BgRGB(0, 0, 2)},
+		{"rgbt(1,2,3)", FgRGBT(1, 2, 3)},
+		{"gray(23)", FgGray(23)},
+		{"bold  bg:rgb(0, 0, 2)\tgray(23)", Bold + BgRGB(0, 0, 2) + FgGray(23)},
+	}
+	for _, test := range tests {
+		color, err := parseColor(test.spec)
+		if err != nil {
+			t.Errorf("parseColor(%q) unexpected error: %s", test.spec, err)
+		} else if color != test.color {
+			t.Errorf("parseColor(%q) = %q, want %q", test.spec, color, test.color)
+		}
+	}
+}
+
+func TestParseColorErrors(t *testing.T) {
+	for _, spec := range []string{
+		"not-a-color",
+		"256",
+		"-1",
+		"rgb(6,0,0)",
+		"rgb(1,2)",
+		"rgb(a,b,c)",
+		"gray(24)",
+		"hsl(1,2,3)",
+		"rgb(1,2,3",
+	} {
+		if color, err := parseColor(spec); err == nil {
+			t.Errorf("parseColor(%q) = %q, want an error", spec, color)
+		}
+	}
+}
+
+func TestColorTokens(t *testing.T) {
+	tests := []struct {
+		spec   string
+		tokens []string
+	}{
+		{"", []string{}},
+		{"  ", []string{}},
+		{"bold red", []string{"bold", "red"}},
+		{" bold\t\tred ", []string{"bold", "red"}},
+		{"bg:rgb(5, 0, 2) gray( 3 )", []string{"bg:rgb(5,0,2)", "gray(3)"}},
+		{"rgb(1, 2, 3", []string{"rgb(1,2,3"}},
+		{"a) b", []string{"a)", "b"}},
+	}
+	for _, test := range tests {
+		if tokens := colorTokens(test.spec); !reflect.DeepEqual(tokens, test.tokens) {
+			t.Errorf("colorTokens(%q) = %q, want %q", test.spec, tokens, test.tokens)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	GenerateColors(false)
+	// the size scale and icons aren't rebuilt by GenerateColors
+	scale := SizeScale["M"]
+	defer func() {
+		GenerateColors(false)
+		SizeScale["M"] = scale
+		delete(aliases, "buf.yaml")
+	}()
+	keepFileAliases(t)
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	writeTestFile(t, configFile, `
+[files]
+GO = "red"
+rs = ["blue", "green"]
+
+[file-aliases]
+JSONC = "JS"
+
+[sizes]
+G = "rgb(5, 2, 0)"
+
+[size-scale]
+M = "100K"
+
+[colors.dir]
+name = "bold bg:rgb(0,0,2) gray(23)"
+
+[colors."my.section"]
+key = "red"
+
+[perms.user]
+deploy = "bright-red"
+
+[icon-aliases]
+"buf.yaml" = "proto"
+`)
+	if err := LoadConfig(configFile); err != nil {
+		t.Fatal(err)
+	}
+
+	if colors := FileColor["go"]; colors.light != NamedFg(Red) || colors.dark != NamedFg(Red) {
+		t.Errorf("FileColor[go] = %#v", colors)
+	}
+	if colors := FileColor["rs"]; colors.light != NamedFg(Blue) || colors.dark != NamedFg(Green) {
+		t.Errorf("FileColor[rs] = %#v", colors)
+	}
+	if alias := FileAliases["jsonc"]; alias != "js" {
+		t.Errorf("FileAliases[jsonc] = %q, want \"js\"", alias)
+	}
+	if color := SizeColor["G"]; color != FgRGB(5, 2, 0) {
+		t.Errorf("SizeColor[G] = %q", color)
+	}
+	if scale := SizeScale["M"]; scale != 100*1024 {
+		t.Errorf("SizeScale[M] = %d, want %d", scale, 100*1024)
+	}
+	if color := ConfigColor["dir"]["name"]; color != Bold+BgRGB(0, 0, 2)+FgGray(23) {
+		t.Errorf("ConfigColor[dir][name] = %q", color)
+	}
+	if color := ConfigColor["my.section"]["key"]; color != NamedFg(Red) {
+		t.Errorf("ConfigColor[my.section][key] = %q", color)
+	}
+	if color := PermsColor["user"]["deploy"]; color != NamedFg(BrightRed) {
+		t.Errorf("PermsColor[user][deploy] = %q", color)
+	}
+	if alias := aliases["buf.yaml"]; alias != "proto" {
+		t.Errorf("aliases[buf.yaml] = %q, want \"proto\"", alias)
+	}
+}
+
+func TestLoadConfigSyntax(t *testing.T) {
+	GenerateColors(false)
+	defer GenerateColors(false)
+	keepFileAliases(t)
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	writeTestFile(t, configFile, `
+[files]
+rs = [
+  "blue",  # the main color
+  "green",
+]
+md = """bold
+red"""
+
+[colors]
+dir.name = "red"
+link.arrow = 'bright-blue'
+`)
+	if err := LoadConfig(configFile); err != nil {
+		t.Fatal(err)
+	}
+
+	if colors := FileColor["rs"]; colors.light != NamedFg(Blue) || colors.dark != NamedFg(Green) {
+		t.Errorf("FileColor[rs] = %#v", colors)
+	}
+	if colors := FileColor["md"]; colors.light != Bold+NamedFg(Red) {
+		t.Errorf("FileColor[md] = %#v", colors)
+	}
+	if color := ConfigColor["dir"]["name"]; color != NamedFg(Red) {
+		t.Errorf("ConfigColor[dir][name] = %q", color)
+	}
+	if color := ConfigColor["link"]["arrow"]; color != NamedFg(BrightBlue) {
+		t.Errorf("ConfigColor[link][arrow] = %q", color)
+	}
+}
+
+func TestLoadConfigOrder(t *testing.T) {
+	GenerateColors(false)
+	defer GenerateColors(false)
+	keepFileAliases(t)
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	writeTestFile(t, configFile, `
+[file-aliases]
+jsonc = "js"
+
+[files]
+jsonc = "red"
+JSONC = "blue"
+`)
+	// an extension in both sections is always an alias, and the keys are applied in order
+	for i := 0; i < 20; i++ {
+		if err := LoadConfig(configFile); err != nil {
+			t.Fatal(err)
+		}
+		if alias := FileAliases["jsonc"]; alias != "js" {
+			t.Fatalf("FileAliases[jsonc] = %q, want \"js\"", alias)
+		}
+		if colors := FileColor["jsonc"]; colors.light != NamedFg(Red) {
+			t.Fatalf("FileColor[jsonc] = %#v", colors)
+		}
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	GenerateColors(false)
+	defer GenerateColors(false)
+	keepFileAliases(t)
+	if err := LoadConfig(filepath.Join(t.TempDir(), "missing.toml")); err != nil {
+		t.Errorf("a missing config file gave an error: %s", err)
+	}
+	tests := []struct {
+		config string
+		err    string
+	}{
+		{"key = \"red\"", "keys must be inside a section, e.g. [files]"},
+		{"[colors]\nkey = \"red\"", "key must be a table, e.g. [colors.key]"},
+		{"[unknown]\nkey = \"red\"", "unknown section [unknown]"},
+		{"[files]\ngo = 1", "go must be a color or an array of 2 colors"},
+		{"[files]\ngo = \"nope\"", "go: invalid color \"nope\": strconv.Atoi: parsing \"nope\": invalid syntax"},
+		{"[file-aliases]\njsonc = true", "jsonc must be a string"},
+		{"[size-scale]\nX = \"1K\"", "X is not a size unit"},
+		{"[size-scale]\nK = \"big\"", "K: invalid size \"big\""},
+	}
+	for _, test := range tests {
+		configFile := filepath.Join(t.TempDir(), "config.toml")
+		writeTestFile(t, configFile, test.config)
+		want := configFile + ": " + test.err
+		if err := LoadConfig(configFile); err == nil || err.Error() != want {
+			t.Errorf("LoadConfig(%q) error = %v, want %q", test.config, err, want)
+		}
+	}
+}
+
+// keepFileAliases restores FileAliases after a test, since GenerateColors doesn't rebuild it.
+func keepFileAliases(t *testing.T) {
+	saved := map[string]string{}
+	for ext, alias := range FileAliases {
+		saved[ext] = alias
+	}
+	t.Cleanup(func() {
+		FileAliases = saved
+	})
+}
@@ -0,0 +1,124 @@
+package lsgo
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// sizeWalker adds up the sizes of everything inside a folder, like `du`. Subfolders are read concurrently
+// when a worker is free. Files with several hard links are set aside rather than added up, so they can be
+// counted once across all the folders of a listing.
+type sizeWalker struct {
+	lister   *Lister
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	links    map[fileKey]linkedFile
+	apparent int64
+	disk     int64
+	errs     []error
+}
+
+// linkedFile is the size of a file with several hard links.
+type linkedFile struct {
+	apparent int64
+	disk     int64
+}
+
+// dirSize returns the total apparent size and on-disk size of a folder, including the folder itself, along
+// with the problems reading any of its contents. The files with several hard links aren't in the totals, but
+// are returned separately, once each.
+func (l *Lister) dirSize(absPath string, info os.FileInfo) (int64, int64, map[fileKey]linkedFile, []error) {
+	walker := sizeWalker{
+		lister:   l,
+		links:    map[fileKey]linkedFile{},
+		apparent: info.Size(),
+		disk:     diskSize(info),
+	}
+	walker.walk(absPath)
+	walker.wg.Wait()
+	return walker.apparent, walker.disk, walker.links, walker.errs
+}
+
+func (w *sizeWalker) walk(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		w.addError(err)
+	}
+	var apparent, disk int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			w.addError(err)
+			continue
+		}
+		if info.IsDir() {
+			apparent += info.Size()
+			disk += diskSize(info)
+			w.walkChild(filepath.Join(dir, entry.Name()))
+			continue
+		}
+		if key, isLinked := hardLinkKey(info); isLinked {
+			w.mu.Lock()
+			w.links[key] = linkedFile{info.Size(), diskSize(info)}
+			w.mu.Unlock()
+			continue
+		}
+		apparent += info.Size()
+		disk += diskSize(info)
+	}
+	w.mu.Lock()
+	w.apparent += apparent
+	w.disk += disk
+	w.mu.Unlock()
+}
+
+// walkChild reads a subfolder in another goroutine if a worker is free, otherwise in this one, so the
+// workers can never all be stuck waiting for each other.
+func (w *sizeWalker) walkChild(dir string) {
+	if !w.lister.tryAcquire() {
+		w.walk(dir)
+		return
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer w.lister.release()
+		w.walk(dir)
+	}()
+}
+
+func (w *sizeWalker) addError(err error) {
+	w.mu.Lock()
+	w.errs = append(w.errs, err)
+	w.mu.Unlock()
+}
+
+// sizeDirs fills in the sizes of the folders in a listing, spreading them over the workers. Like `du`, a
+// file linked from several of the folders only counts toward the first one, in the order they were read.
+// Each folder is added up from scratch, so Lister.Walk reads the folders deep in the tree once per level.
+func (l *Lister) sizeDirs(listing *Listing) {
+	links := make([]map[fileKey]linkedFile, len(listing.Dirs))
+	errs := make([][]error, len(listing.Dirs))
+	l.forEach(len(listing.Dirs), func(i int) {
+		entry := listing.Dirs[i]
+		// symlinks to folders are not followed, like `du`
+		if entry.Info.IsDir() {
+			entry.Size, entry.DiskSize, links[i], errs[i] = l.dirSize(entry.Path, entry.Info)
+		}
+	})
+
+	// settle the hard links now that all the folders are read, so which one they count toward doesn't
+	// depend on which was read first
+	counted := map[fileKey]bool{}
+	for i, entry := range listing.Dirs {
+		for key, file := range links[i] {
+			if !counted[key] {
+				counted[key] = true
+				entry.Size += file.apparent
+				entry.DiskSize += file.disk
+			}
+		}
+		listing.Errors = append(listing.Errors, errs[i]...)
+	}
+}
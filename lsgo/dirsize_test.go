@@ -0,0 +1,62 @@
+package lsgo
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDirSize(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "a", "shared"), strings.Repeat("x", 1000))
+	writeTestFile(t, filepath.Join(dir, "a", "deep", "file"), strings.Repeat("x", 300))
+	writeTestFile(t, filepath.Join(dir, "c", "file"), strings.Repeat("x", 20))
+	if err := os.Mkdir(filepath.Join(dir, "b"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// hard links aren't told apart on windows
+	if runtime.GOOS != "windows" {
+		// linked twice from b, and once from a, but only counted in a
+		for _, name := range []string{"b/one", "b/two"} {
+			if err := os.Link(filepath.Join(dir, "a", "shared"), filepath.Join(dir, name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// the apparent size of a folder itself depends on the filesystem
+	folderSize := func(name string) int64 {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Size()
+	}
+	expected := map[string]int64{
+		"a": folderSize("a") + folderSize("a/deep") + 1300,
+		"b": folderSize("b"),
+		"c": folderSize("c") + 20,
+	}
+
+	// with different numbers of workers, so the folders are read in different orders
+	for _, workers := range []int{1, 2, 16} {
+		for i := 0; i < 5; i++ {
+			listing := listTestDir(t, Options{DirSize: true, Workers: workers}, dir)
+			if len(listing.Errors) > 0 {
+				t.Fatal(listing.Errors)
+			}
+			for _, entry := range listing.Dirs {
+				if entry.Size != expected[entry.Basename] {
+					t.Errorf("%d workers: the size of %s is %d, expected %d",
+						workers, entry.Basename, entry.Size, expected[entry.Basename])
+				}
+				if entry.DiskSize < entry.OwnDiskSize {
+					t.Errorf("%d workers: %s takes up %d bytes on disk, less than the folder itself",
+						workers, entry.Basename, entry.DiskSize)
+				}
+			}
+		}
+	}
+}
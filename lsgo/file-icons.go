@@ -1,10 +1,11 @@
-package main
+package lsgo
 
 import (
 	"strings"
 )
 
-func getIconForFile(name, ext string) string {
+// GetIconForFile picks the nerd font glyph for a file, preferring its full name over its extension.
+func GetIconForFile(name, ext string) string {
 	// default icon for all files. try to find a better one though...
 	icon := icons["file"]
 
@@ -39,7 +40,8 @@ func getIconForFile(name, ext string) string {
 	return icon
 }
 
-func getIconForFolder(name string) string {
+// GetIconForFolder picks the nerd font glyph for a folder by its name.
+func GetIconForFolder(name string) string {
 	icon := folders["folder"]
 	betterIcon, hasBetterIcon := folders[name]
 	if hasBetterIcon {
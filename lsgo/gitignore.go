@@ -0,0 +1,230 @@
+package lsgo
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// A single line of a .gitignore file.
+type ignorePattern struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+	// patterns without a slash match the base name of a path at any depth
+	basenameOnly bool
+}
+
+// The patterns of a global ignore file, which apply to the paths under `base`.
+type globalIgnore struct {
+	base     string
+	patterns []ignorePattern
+}
+
+// ignoreMatcher decides which paths under a root folder are ignored, following the rules of .gitignore
+// files. Patterns are read from the files named in `fileNames` in each folder, with the patterns of deeper
+// folders taking precedence over the shallower ones, and from any global files added with `addGlobalFile`,
+// which have the lowest precedence.
+type ignoreMatcher struct {
+	root      string
+	fileNames []string
+	global    []globalIgnore
+	// patterns for each folder, keyed by the folder path relative to the root, loaded as needed
+	perDir map[string][]ignorePattern
+	cache  map[string]bool
+}
+
+func newIgnoreMatcher(root string, fileNames []string) *ignoreMatcher {
+	return &ignoreMatcher{
+		root:      root,
+		fileNames: fileNames,
+		perDir:    map[string][]ignorePattern{},
+		cache:     map[string]bool{},
+	}
+}
+
+// addGlobalFile reads patterns which apply relative to a folder under the root, e.g. .git/info/exclude
+// applies relative to the root itself (""). Missing files are silently skipped.
+func (m *ignoreMatcher) addGlobalFile(filePath string, base string) {
+	m.global = append(m.global, globalIgnore{base, readIgnoreFile(filePath)})
+}
+
+// isIgnored checks a path relative to the root. A path is also ignored when any of its parent folders are,
+// since git does not look inside ignored folders at all.
+func (m *ignoreMatcher) isIgnored(relPath string, isDir bool) bool {
+	if relPath == "" || relPath == "." {
+		return false
+	}
+	cacheKey := relPath
+	if isDir {
+		cacheKey += "/"
+	}
+	if ignored, isCached := m.cache[cacheKey]; isCached {
+		return ignored
+	}
+
+	ignored := false
+	parent := path.Dir(relPath)
+	if parent != "." && m.isIgnored(parent, true) {
+		ignored = true
+	} else {
+		ignored = m.match(relPath, isDir)
+	}
+	m.cache[cacheKey] = ignored
+	return ignored
+}
+
+// match checks the patterns that apply to a path, without considering its parent folders. The last
+// matching pattern wins.
+func (m *ignoreMatcher) match(relPath string, isDir bool) bool {
+	ignored := false
+	apply := func(patterns []ignorePattern, base string) {
+		rel := relPath
+		if base != "" {
+			rel = strings.TrimPrefix(relPath, base+"/")
+		}
+		for _, pattern := range patterns {
+			if pattern.dirOnly && !isDir {
+				continue
+			}
+			target := rel
+			if pattern.basenameOnly {
+				target = path.Base(rel)
+			}
+			if pattern.re.MatchString(target) {
+				ignored = !pattern.negate
+			}
+		}
+	}
+
+	for _, global := range m.global {
+		if global.base == "" || strings.HasPrefix(relPath, global.base+"/") {
+			apply(global.patterns, global.base)
+		}
+	}
+	// walk down from the root to the folder containing the path
+	dirs := []string{""}
+	if parent := path.Dir(relPath); parent != "." {
+		parts := strings.Split(parent, "/")
+		for i := range parts {
+			dirs = append(dirs, strings.Join(parts[:i+1], "/"))
+		}
+	}
+	for _, dir := range dirs {
+		apply(m.patternsForDir(dir), dir)
+	}
+	return ignored
+}
+
+func (m *ignoreMatcher) patternsForDir(dir string) []ignorePattern {
+	patterns, isLoaded := m.perDir[dir]
+	if isLoaded {
+		return patterns
+	}
+	for _, fileName := range m.fileNames {
+		patterns = append(patterns, readIgnoreFile(filepath.Join(m.root, filepath.FromSlash(dir), fileName))...)
+	}
+	m.perDir[dir] = patterns
+	return patterns
+}
+
+func readIgnoreFile(filePath string) []ignorePattern {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	patterns := []ignorePattern{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if pattern, isPattern := parseIgnoreLine(scanner.Text()); isPattern {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+func parseIgnoreLine(line string) (ignorePattern, bool) {
+	pattern := ignorePattern{}
+	line = strings.TrimSuffix(line, "\r")
+	// trailing spaces are ignored unless they are escaped
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if line == "" || line[0] == '#' {
+		return pattern, false
+	}
+	if line[0] == '!' {
+		pattern.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return pattern, false
+	}
+	// a slash at the beginning or middle anchors the pattern to the folder of the .gitignore file
+	if strings.Contains(line, "/") {
+		line = strings.TrimPrefix(line, "/")
+	} else {
+		pattern.basenameOnly = true
+	}
+
+	re, err := regexp.Compile("^" + globToRegexp(line) + "$")
+	if err != nil {
+		return pattern, false
+	}
+	pattern.re = re
+	return pattern, true
+}
+
+// globToRegexp translates the wildcards of a .gitignore pattern, including "**" for any number of folders.
+func globToRegexp(glob string) string {
+	var re strings.Builder
+	for i := 0; i < len(glob); i++ {
+		char := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			// zero or more leading folders
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			// everything inside
+			re.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			re.WriteString(".*")
+			i++
+		case char == '*':
+			re.WriteString("[^/]*")
+		case char == '?':
+			re.WriteString("[^/]")
+		case char == '[':
+			end := strings.Index(glob[i+1:], "]")
+			if end < 0 {
+				re.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			re.WriteString("[" + strings.ReplaceAll(class, "\\", "\\\\") + "]")
+			i += end + 1
+		case char == '\\' && i+1 < len(glob):
+			i++
+			re.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			re.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return re.String()
+}
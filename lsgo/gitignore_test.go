@@ -0,0 +1,165 @@
+package lsgo
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseIgnoreLine(t *testing.T) {
+	tests := []struct {
+		line         string
+		isPattern    bool
+		negate       bool
+		dirOnly      bool
+		basenameOnly bool
+	}{
+		{"", false, false, false, false},
+		{"# comment", false, false, false, false},
+		{"   ", false, false, false, false},
+		{"/", false, false, true, false},
+		{"*.log", true, false, false, true},
+		{"*.log  ", true, false, false, true},
+		{"build/", true, false, true, true},
+		{"/build", true, false, false, false},
+		{"docs/*.md", true, false, false, false},
+		{"!keep.log", true, true, false, true},
+		{"\\!important", true, false, false, true},
+		{"\\#hash", true, false, false, true},
+		{"node_modules/\r", true, false, true, true},
+	}
+	for _, test := range tests {
+		pattern, isPattern := parseIgnoreLine(test.line)
+		if isPattern != test.isPattern {
+			t.Errorf("parseIgnoreLine(%q) is a pattern: %v, want %v", test.line, isPattern, test.isPattern)
+			continue
+		}
+		if !isPattern {
+			continue
+		}
+		if pattern.negate != test.negate || pattern.dirOnly != test.dirOnly || pattern.basenameOnly != test.basenameOnly {
+			t.Errorf("parseIgnoreLine(%q) = negate %v, dirOnly %v, basenameOnly %v, want %v, %v, %v", test.line,
+				pattern.negate, pattern.dirOnly, pattern.basenameOnly, test.negate, test.dirOnly, test.basenameOnly)
+		}
+	}
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob    string
+		path    string
+		matches bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "src/main.go", false},
+		{"?.txt", "a.txt", true},
+		{"?.txt", "ab.txt", false},
+		{"[abc].txt", "b.txt", true},
+		{"[!abc].txt", "b.txt", false},
+		{"[!abc].txt", "d.txt", true},
+		{"**/vendor", "vendor", true},
+		{"**/vendor", "a/b/vendor", true},
+		{"logs/**", "logs/a/b.log", true},
+		{"logs/**", "logs", false},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"a.b", "axb", false},
+		{"\\*", "*", true},
+		{"\\*", "x", false},
+		{"[unclosed", "[unclosed", true},
+	}
+	for _, test := range tests {
+		pattern, isPattern := parseIgnoreLine(test.glob)
+		if !isPattern {
+			t.Errorf("parseIgnoreLine(%q) isn't a pattern", test.glob)
+			continue
+		}
+		if matches := pattern.re.MatchString(test.path); matches != test.matches {
+			t.Errorf("pattern %q matches %q: %v, want %v", test.glob, test.path, matches, test.matches)
+		}
+	}
+}
+
+func TestIgnoreMatcher(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, ".gitignore"), "*.log\n!keep.log\nbuild/\n/top.txt\n")
+	writeTestFile(t, filepath.Join(root, "sub", ".gitignore"), "!debug.log\nlocal.txt\n")
+	writeTestFile(t, filepath.Join(root, "exclude"), "*.tmp\n")
+
+	matcher := newIgnoreMatcher(root, []string{".gitignore"})
+	matcher.addGlobalFile(filepath.Join(root, "exclude"), "")
+	matcher.addGlobalFile(filepath.Join(root, "missing"), "")
+
+	tests := []struct {
+		relPath string
+		isDir   bool
+		ignored bool
+	}{
+		{"", true, false},
+		{"main.go", false, false},
+		{"error.log", false, true},
+		{"keep.log", false, false},
+		{"sub/error.log", false, true},
+		{"sub/debug.log", false, false},
+		{"debug.log", false, true},
+		{"build", true, true},
+		{"build", false, false},
+		{"build/out.bin", false, true},
+		{"sub/build", true, true},
+		{"top.txt", false, true},
+		{"sub/top.txt", false, false},
+		{"local.txt", false, false},
+		{"sub/local.txt", false, true},
+		{"scratch.tmp", false, true},
+		{"sub/deep/scratch.tmp", false, true},
+	}
+	for _, test := range tests {
+		if ignored := matcher.isIgnored(test.relPath, test.isDir); ignored != test.ignored {
+			t.Errorf("isIgnored(%q, %v) = %v, want %v", test.relPath, test.isDir, ignored, test.ignored)
+		}
+	}
+}
+
+func TestListIgnoredItems(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, ".lsgoignore"), "*.log\nbuild/\n")
+	extraIgnore := filepath.Join(dir, "extra-ignore")
+	writeTestFile(t, extraIgnore, "*.tmp\n")
+	for _, name := range []string{"error.log", "main.go", "scratch.tmp", "build/out.bin", "src/debug.log",
+		"src/lib.go"} {
+		writeTestFile(t, filepath.Join(dir, name), "")
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		visited []string
+	}{
+		{"everything", Options{}, []string{
+			dir, "build", "src", "error.log", "extra-ignore", "main.go", "scratch.tmp",
+			filepath.Join(dir, "build"), "out.bin",
+			filepath.Join(dir, "src"), "debug.log", "lib.go",
+		}},
+		{".lsgoignore", Options{GitIgnore: true}, []string{
+			dir, "src", "extra-ignore", "main.go", "scratch.tmp",
+			filepath.Join(dir, "src"), "lib.go",
+		}},
+		{"extra ignore file", Options{GitIgnore: true, IgnoreFiles: []string{extraIgnore}}, []string{
+			dir, "src", "extra-ignore", "main.go",
+			filepath.Join(dir, "src"), "lib.go",
+		}},
+	}
+	for _, test := range tests {
+		// the path of each folder, followed by the names in it
+		visited := []string{}
+		NewLister(test.opts).Walk(dir, func(listing *Listing) {
+			visited = append(visited, listing.Path)
+			for _, entry := range listing.Entries() {
+				visited = append(visited, entry.Filename())
+			}
+		})
+		if !reflect.DeepEqual(visited, test.visited) {
+			t.Errorf("%s: visited %q, want %q", test.name, visited, test.visited)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package lsgo
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Object types as numbered in pack files.
+var packObjectTypes = map[byte]string{1: "commit", 2: "tree", 3: "blob", 4: "tag"}
+
+const (
+	packOfsDelta = 6
+	packRefDelta = 7
+)
+
+// gitPack is a pack file along with its (version 2) index, which maps object names to offsets in the pack.
+type gitPack struct {
+	packPath string
+	hashSize int
+	fanout   [256]uint32
+	names    []byte
+	offsets  []byte
+	large    []byte
+}
+
+func openPacks(packDir string, hashSize int) ([]*gitPack, error) {
+	idxPaths, err := filepath.Glob(filepath.Join(packDir, "*.idx"))
+	if err != nil {
+		return nil, err
+	}
+	packs := []*gitPack{}
+	for _, idxPath := range idxPaths {
+		pack, err := openPack(idxPath, hashSize)
+		if err != nil {
+			return nil, err
+		}
+		packs = append(packs, pack)
+	}
+	return packs, nil
+}
+
+func openPack(idxPath string, hashSize int) (*gitPack, error) {
+	data, err := os.ReadFile(idxPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 8+256*4 || !bytes.Equal(data[:4], []byte("\xfftOc")) || binary.BigEndian.Uint32(data[4:8]) != 2 {
+		return nil, fmt.Errorf("%s: unsupported pack index", idxPath)
+	}
+	pack := gitPack{
+		packPath: strings.TrimSuffix(idxPath, ".idx") + ".pack",
+		hashSize: hashSize,
+	}
+	for i := range pack.fanout {
+		pack.fanout[i] = binary.BigEndian.Uint32(data[8+i*4:])
+	}
+	count := int(pack.fanout[255])
+	// the names are followed by a CRC32 for each object, then the offsets, then any 64-bit offsets
+	namesStart := 8 + 256*4
+	offsetsStart := namesStart + count*hashSize + count*4
+	largeStart := offsetsStart + count*4
+	if largeStart > len(data) {
+		return nil, fmt.Errorf("%s: truncated pack index", idxPath)
+	}
+	pack.names = data[namesStart : namesStart+count*hashSize]
+	pack.offsets = data[offsetsStart:largeStart]
+	pack.large = data[largeStart:]
+	return &pack, nil
+}
+
+// find looks up the offset of an object in the pack with a binary search of the sorted names.
+func (pack *gitPack) find(rawHash []byte) (int64, bool) {
+	lo := 0
+	if rawHash[0] > 0 {
+		lo = int(pack.fanout[rawHash[0]-1])
+	}
+	hi := int(pack.fanout[rawHash[0]])
+	i := lo + sort.Search(hi-lo, func(i int) bool {
+		name := pack.names[(lo+i)*pack.hashSize : (lo+i+1)*pack.hashSize]
+		return bytes.Compare(name, rawHash) >= 0
+	})
+	if i >= hi || !bytes.Equal(pack.names[i*pack.hashSize:(i+1)*pack.hashSize], rawHash) {
+		return 0, false
+	}
+	offset := binary.BigEndian.Uint32(pack.offsets[i*4:])
+	// offsets with the high bit set are indices into the table of 64-bit offsets
+	if offset&0x80000000 != 0 {
+		largeIdx := int(offset&0x7fffffff) * 8
+		if largeIdx+8 > len(pack.large) {
+			return 0, false
+		}
+		return int64(binary.BigEndian.Uint64(pack.large[largeIdx:])), true
+	}
+	return int64(offset), true
+}
+
+// readAt reads the object at an offset in the pack, resolving deltas against their base objects.
+func (pack *gitPack) readAt(offset int64, repo *gitRepo) (string, []byte, error) {
+	file, err := os.Open(pack.packPath)
+	if err != nil {
+		return "", nil, err
+	}
+	defer file.Close()
+	return pack.readObjectAt(file, offset, repo)
+}
+
+func (pack *gitPack) readObjectAt(file *os.File, offset int64, repo *gitRepo) (string, []byte, error) {
+	// the header is a type and a little-endian varint size, and can't be longer than this
+	header := make([]byte, 32+pack.hashSize)
+	n, readErr := file.ReadAt(header, offset)
+	if readErr != nil && readErr != io.EOF {
+		return "", nil, readErr
+	}
+	header = header[:n]
+	if len(header) == 0 {
+		return "", nil, fmt.Errorf("invalid pack offset %d", offset)
+	}
+
+	objType := (header[0] >> 4) & 7
+	pos := 1
+	for c := header[0]; c&0x80 != 0 && pos < len(header); pos++ {
+		c = header[pos]
+	}
+
+	var base []byte
+	var baseType string
+	var err error
+	switch objType {
+	case packOfsDelta:
+		relative, n := readOffsetVarint(header[pos:])
+		pos += n
+		baseType, base, err = pack.readObjectAt(file, offset-int64(relative), repo)
+	case packRefDelta:
+		if pos+pack.hashSize > len(header) {
+			return "", nil, fmt.Errorf("invalid pack object at %d", offset)
+		}
+		baseHash := hex.EncodeToString(header[pos : pos+pack.hashSize])
+		pos += pack.hashSize
+		baseType, base, err = repo.readObject(baseHash)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	zr, err := zlib.NewReader(io.NewSectionReader(file, offset+int64(pos), 1<<62))
+	if err != nil {
+		return "", nil, err
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if base != nil {
+		data, err = applyDelta(base, data)
+		return baseType, data, err
+	}
+	typeName, isKnown := packObjectTypes[objType]
+	if !isKnown {
+		return "", nil, fmt.Errorf("unknown pack object type %d", objType)
+	}
+	return typeName, data, nil
+}
+
+// applyDelta rebuilds an object from its base and a delta, which is a list of instructions that either copy
+// a range of the base or insert new data.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	readSize := func() int {
+		size, shift := 0, 0
+		for len(delta) > 0 {
+			c := delta[0]
+			delta = delta[1:]
+			size |= int(c&0x7f) << shift
+			shift += 7
+			if c&0x80 == 0 {
+				break
+			}
+		}
+		return size
+	}
+	if readSize() != len(base) {
+		return nil, fmt.Errorf("delta does not match its base")
+	}
+	result := make([]byte, 0, readSize())
+
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+		if op&0x80 != 0 {
+			// the low 4 bits say which bytes of the offset are present, and the next 3 bits for the size
+			var copyOffset, copySize int
+			for i := 0; i < 7; i++ {
+				if op&(1<<i) == 0 {
+					continue
+				}
+				if len(delta) == 0 {
+					return nil, fmt.Errorf("truncated delta")
+				}
+				if i < 4 {
+					copyOffset |= int(delta[0]) << (8 * i)
+				} else {
+					copySize |= int(delta[0]) << (8 * (i - 4))
+				}
+				delta = delta[1:]
+			}
+			if copySize == 0 {
+				copySize = 0x10000
+			}
+			if copyOffset+copySize > len(base) {
+				return nil, fmt.Errorf("delta copies out of range")
+			}
+			result = append(result, base[copyOffset:copyOffset+copySize]...)
+		} else if op != 0 {
+			if int(op) > len(delta) {
+				return nil, fmt.Errorf("truncated delta")
+			}
+			result = append(result, delta[:op]...)
+			delta = delta[op:]
+		} else {
+			return nil, fmt.Errorf("invalid delta instruction")
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,194 @@
+package lsgo
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestApplyDelta(t *testing.T) {
+	base := []byte("hello world")
+	tests := []struct {
+		name   string
+		delta  []byte
+		result string
+		err    string
+	}{
+		{"copy all", []byte{11, 11, 0x91, 0, 11}, "hello world", ""},
+		{"insert", []byte{11, 3, 3, 'a', 'b', 'c'}, "abc", ""},
+		{"copy and insert", []byte{11, 11, 0x90, 5, 1, ' ', 0x91, 6, 5}, "hello world", ""},
+		{"copy with offset", []byte{11, 5, 0x91, 6, 5}, "world", ""},
+		{"copy offset only", []byte{11, 0}, "", ""},
+		{"wrong base size", []byte{10, 0}, "", "delta does not match its base"},
+		{"copy out of range", []byte{11, 5, 0x91, 8, 5}, "", "delta copies out of range"},
+		{"truncated copy", []byte{11, 5, 0x91, 6}, "", "truncated delta"},
+		{"truncated insert", []byte{11, 5, 5, 'a'}, "", "truncated delta"},
+		{"zero instruction", []byte{11, 5, 0}, "", "invalid delta instruction"},
+	}
+	for _, test := range tests {
+		result, err := applyDelta(base, test.delta)
+		errStr := ""
+		if err != nil {
+			errStr = err.Error()
+		}
+		if string(result) != test.result || errStr != test.err {
+			t.Errorf("%s: applyDelta = %q, %q, want %q, %q", test.name, result, errStr, test.result, test.err)
+		}
+	}
+}
+
+// testPackObject is an object to put in a test pack. Deltas are against the object at baseIndex.
+type testPackObject struct {
+	objType   byte
+	data      []byte
+	baseIndex int
+	// the contents once the delta is applied, which its name is the hash of
+	result []byte
+}
+
+// writePack writes a pack file and its version 2 index into packDir, and returns the names of the objects.
+func writePack(t *testing.T, packDir string, objects []testPackObject, typeNames []string) []string {
+	t.Helper()
+	pack := []byte("PACK")
+	pack = binary.BigEndian.AppendUint32(pack, 2)
+	pack = binary.BigEndian.AppendUint32(pack, uint32(len(objects)))
+
+	names := make([]string, len(objects))
+	rawNames := make([][]byte, len(objects))
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = len(pack)
+		contents := object.data
+		if object.result != nil {
+			contents = object.result
+		}
+		rawName := sha1.Sum([]byte(fmt.Sprintf("%s %d\x00%s", typeNames[i], len(contents), contents)))
+		rawNames[i] = rawName[:]
+		names[i] = hex.EncodeToString(rawName[:])
+
+		// the type and the size of the (uncompressed) data, 4 bits and then 7 bits at a time
+		size := len(object.data)
+		header := []byte{object.objType<<4 | byte(size&0x0f)}
+		for size >>= 4; size > 0; size >>= 7 {
+			header[len(header)-1] |= 0x80
+			header = append(header, byte(size&0x7f))
+		}
+		switch object.objType {
+		case packOfsDelta:
+			header = append(header, encodeOffsetVarint(uint64(offsets[i]-offsets[object.baseIndex]))...)
+		case packRefDelta:
+			header = append(header, rawNames[object.baseIndex]...)
+		}
+		pack = append(pack, header...)
+		pack = append(pack, zlibCompress(object.data)...)
+	}
+
+	order := make([]int, len(objects))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return bytes.Compare(rawNames[order[a]], rawNames[order[b]]) < 0
+	})
+	idx := []byte("\xfftOc")
+	idx = binary.BigEndian.AppendUint32(idx, 2)
+	for first := 0; first < 256; first++ {
+		count := 0
+		for _, rawName := range rawNames {
+			if int(rawName[0]) <= first {
+				count++
+			}
+		}
+		idx = binary.BigEndian.AppendUint32(idx, uint32(count))
+	}
+	for _, i := range order {
+		idx = append(idx, rawNames[i]...)
+	}
+	for range order {
+		idx = binary.BigEndian.AppendUint32(idx, 0)
+	}
+	for _, i := range order {
+		idx = binary.BigEndian.AppendUint32(idx, uint32(offsets[i]))
+	}
+
+	writeTestFile(t, filepath.Join(packDir, "pack-test.pack"), string(pack))
+	writeTestFile(t, filepath.Join(packDir, "pack-test.idx"), string(idx))
+	return names
+}
+
+func TestReadPackedObjects(t *testing.T) {
+	commonDir := t.TempDir()
+	base := []byte("package main\n\nfunc main() {}\n")
+	edited := []byte("package main\n\nfunc main() { run() }\n")
+	// copy up to the "{", insert " run() ", and copy the rest
+	delta := []byte{byte(len(base)), byte(len(edited)), 0x90, 27, 7, ' ', 'r', 'u', 'n', '(', ')', ' ', 0x91, 27, 2}
+	renamed := []byte("package app\n\nfunc main() {}\n")
+	// insert "package app" and copy everything after "package main"
+	refDelta := append([]byte{byte(len(base)), byte(len(renamed)), 11}, "package app"...)
+	refDelta = append(refDelta, 0x91, 12, byte(len(base)-12))
+	objects := []testPackObject{
+		{objType: 3, data: base},
+		{objType: 1, data: []byte("tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\ninitial\n")},
+		{objType: packOfsDelta, data: delta, baseIndex: 0, result: edited},
+		{objType: packRefDelta, data: refDelta, baseIndex: 0, result: renamed},
+		{objType: 3, data: bytes.Repeat([]byte("long "), 100)},
+	}
+	names := writePack(t, filepath.Join(commonDir, "objects", "pack"), objects,
+		[]string{"blob", "commit", "blob", "blob", "blob"})
+	repo := &gitRepo{commonDir: commonDir, hashSize: sha1.Size}
+
+	tests := []struct {
+		name    string
+		objType string
+		data    []byte
+	}{
+		{names[0], "blob", base},
+		{names[1], "commit", objects[1].data},
+		{names[2], "blob", edited},
+		{names[3], "blob", renamed},
+		{names[4], "blob", objects[4].data},
+	}
+	for _, test := range tests {
+		objType, data, err := repo.readObject(test.name)
+		if err != nil {
+			t.Errorf("readObject(%s) unexpected error: %s", test.name, err)
+		} else if objType != test.objType || !bytes.Equal(data, test.data) {
+			t.Errorf("readObject(%s) = %q, %q, want %q, %q", test.name, objType, data, test.objType, test.data)
+		}
+	}
+
+	missing := hex.EncodeToString(make([]byte, sha1.Size))
+	if _, _, err := repo.readObject(missing); err == nil {
+		t.Errorf("readObject of a missing object didn't give an error")
+	}
+	if _, _, err := repo.readObject("abc"); err == nil {
+		t.Errorf("readObject of a short name didn't give an error")
+	}
+}
+
+func TestOpenPackErrors(t *testing.T) {
+	dir := t.TempDir()
+	valid := append([]byte("\xfftOc\x00\x00\x00\x02"), make([]byte, 256*4)...)
+	binary.BigEndian.PutUint32(valid[8+255*4:], 1)
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{"empty", nil, "unsupported pack index"},
+		{"version 1", append([]byte("\xfftOc\x00\x00\x00\x01"), valid[8:]...), "unsupported pack index"},
+		{"truncated", valid, "truncated pack index"},
+	}
+	for _, test := range tests {
+		idxPath := filepath.Join(dir, test.name+".idx")
+		writeTestFile(t, idxPath, string(test.data))
+		if _, err := openPack(idxPath, sha1.Size); err == nil || err.Error() != idxPath+": "+test.err {
+			t.Errorf("%s: error = %v, want %q", test.name, err, idxPath+": "+test.err)
+		}
+	}
+}
@@ -0,0 +1,460 @@
+package lsgo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// gitRepo reads just enough of a repository's .git folder to work out the status of the files in it,
+// without needing the git binary.
+type gitRepo struct {
+	// the root of the working tree
+	root string
+	// the .git folder, and the folder with the objects and refs. they differ for linked worktrees.
+	gitDir    string
+	commonDir string
+	hashSize  int
+	newHash   func() hash.Hash
+
+	// entries of the index in stage 0, sorted by path
+	index      []*indexEntry
+	indexPaths map[string]*indexEntry
+	// paths with merge conflicts, i.e. index entries in stages 1-3
+	conflicts map[string]bool
+	// blobs in the tree of the HEAD commit, keyed by path
+	head map[string]treeEntry
+
+	ignore *ignoreMatcher
+	packs  []*gitPack
+	// the status of each path, keyed by the path relative to the root
+	statusCache map[string]string
+}
+
+type indexEntry struct {
+	path     string
+	mode     uint32
+	hash     string
+	size     uint32
+	mtimeSec uint32
+	mtimeNs  uint32
+	stage    int
+}
+
+type treeEntry struct {
+	mode uint32
+	hash string
+}
+
+// findGitRepo finds the repo containing a folder by looking for a .git folder (or file, for worktrees and
+// submodules) in it and each of its parents.
+func (l *Lister) findGitRepo(absDir string) *gitRepo {
+	if repo, isCached := l.gitRepos[absDir]; isCached {
+		return repo
+	}
+	var repo *gitRepo
+	if filepath.Base(absDir) == ".git" {
+		// the contents of the .git folder aren't part of the working tree
+		repo = nil
+	} else if gitDir := resolveGitDir(filepath.Join(absDir, ".git")); gitDir != "" {
+		var err error
+		repo, err = openGitRepo(absDir, gitDir)
+		if err != nil {
+			repo = nil
+		}
+	} else if parent := filepath.Dir(absDir); parent != absDir {
+		repo = l.findGitRepo(parent)
+	}
+	l.gitRepos[absDir] = repo
+	return repo
+}
+
+// resolveGitDir returns the path to the git folder, following "gitdir:" files, or "" if there is none.
+func resolveGitDir(dotGit string) string {
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return ""
+	}
+	if info.IsDir() {
+		return dotGit
+	}
+	contents, err := os.ReadFile(dotGit)
+	if err != nil || !bytes.HasPrefix(contents, []byte("gitdir:")) {
+		return ""
+	}
+	gitDir := strings.TrimSpace(string(contents[len("gitdir:"):]))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(dotGit), gitDir)
+	}
+	return gitDir
+}
+
+func openGitRepo(root, gitDir string) (*gitRepo, error) {
+	repo := gitRepo{
+		root:        root,
+		gitDir:      gitDir,
+		commonDir:   gitDir,
+		hashSize:    sha1.Size,
+		newHash:     sha1.New,
+		statusCache: map[string]string{},
+	}
+	if commonDir, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		repo.commonDir = strings.TrimSpace(string(commonDir))
+		if !filepath.IsAbs(repo.commonDir) {
+			repo.commonDir = filepath.Join(gitDir, repo.commonDir)
+		}
+	}
+	config := filepath.Join(repo.commonDir, "config")
+	if strings.EqualFold(gitConfigValue(config, "extensions", "objectformat"), "sha256") {
+		repo.hashSize = sha256.Size
+		repo.newHash = sha256.New
+	}
+
+	if err := repo.readIndex(); err != nil {
+		return nil, err
+	}
+	repo.head = map[string]treeEntry{}
+	if headTree, err := repo.headTree(); err == nil && headTree != "" {
+		if err := repo.readTree(headTree, "", repo.head); err != nil {
+			return nil, err
+		}
+	}
+
+	repo.ignore = newIgnoreMatcher(root, []string{".gitignore"})
+	repo.ignore.addGlobalFile(globalExcludesFile(config), "")
+	repo.ignore.addGlobalFile(filepath.Join(repo.commonDir, "info", "exclude"), "")
+	return &repo, nil
+}
+
+// globalExcludesFile finds the file configured with core.excludesFile, falling back to the default
+// location of $XDG_CONFIG_HOME/git/ignore
+func globalExcludesFile(repoConfig string) string {
+	home := os.Getenv("HOME")
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+	excludesFile := gitConfigValue(repoConfig, "core", "excludesfile")
+	if excludesFile == "" {
+		excludesFile = gitConfigValue(filepath.Join(home, ".gitconfig"), "core", "excludesfile")
+	}
+	if excludesFile == "" {
+		excludesFile = gitConfigValue(filepath.Join(configHome, "git", "config"), "core", "excludesfile")
+	}
+	if excludesFile == "" {
+		return filepath.Join(configHome, "git", "ignore")
+	}
+	if strings.HasPrefix(excludesFile, "~/") {
+		excludesFile = filepath.Join(home, excludesFile[2:])
+	}
+	return excludesFile
+}
+
+// gitConfigValue does a simple lookup of a key in a git config file. Section and key names are
+// case-insensitive. Includes and subsections are not supported.
+func gitConfigValue(configFile, section, key string) string {
+	file, err := os.Open(configFile)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	currentSection := ""
+	value := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			currentSection = strings.ToLower(strings.Trim(line, "[]"))
+			continue
+		}
+		if currentSection != section {
+			continue
+		}
+		name, val, hasVal := strings.Cut(line, "=")
+		if hasVal && strings.EqualFold(strings.TrimSpace(name), key) {
+			value = strings.Trim(strings.TrimSpace(val), "\"")
+		}
+	}
+	return value
+}
+
+// readIndex parses the .git/index file, which lists every tracked file along with the stat info from when
+// it was last added. Versions 2, 3, and 4 of the format are supported.
+func (repo *gitRepo) readIndex() error {
+	repo.indexPaths = map[string]*indexEntry{}
+	repo.conflicts = map[string]bool{}
+	data, err := os.ReadFile(filepath.Join(repo.gitDir, "index"))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if len(data) < 12 || string(data[:4]) != "DIRC" {
+		return fmt.Errorf("invalid index file")
+	}
+	version := binary.BigEndian.Uint32(data[4:8])
+	count := binary.BigEndian.Uint32(data[8:12])
+	if version < 2 || version > 4 {
+		return fmt.Errorf("unsupported index version %d", version)
+	}
+
+	// ctime, mtime, dev, ino, mode, uid, gid, size, then the hash and the flags
+	statSize := 40
+	offset := 12
+	prevPath := ""
+	for i := uint32(0); i < count; i++ {
+		entryStart := offset
+		if offset+statSize+repo.hashSize+2 > len(data) {
+			return fmt.Errorf("truncated index file")
+		}
+		field := func(n int) uint32 {
+			return binary.BigEndian.Uint32(data[offset+n*4:])
+		}
+		entry := indexEntry{
+			mtimeSec: field(2),
+			mtimeNs:  field(3),
+			mode:     field(6),
+			size:     field(9),
+		}
+		offset += statSize
+		entry.hash = hex.EncodeToString(data[offset : offset+repo.hashSize])
+		offset += repo.hashSize
+		flags := binary.BigEndian.Uint16(data[offset:])
+		offset += 2
+		entry.stage = int(flags>>12) & 3
+		if flags&0x4000 != 0 && version >= 3 {
+			// extended flags
+			offset += 2
+		}
+
+		if version == 4 {
+			// the path is stored as the number of bytes to remove from the previous path, and a suffix to append
+			strip, n := readOffsetVarint(data[offset:])
+			offset += n
+			end := bytes.IndexByte(data[offset:], 0)
+			if end < 0 || int(strip) > len(prevPath) {
+				return fmt.Errorf("invalid index file")
+			}
+			entry.path = prevPath[:len(prevPath)-int(strip)] + string(data[offset:offset+end])
+			offset += end + 1
+		} else {
+			end := bytes.IndexByte(data[offset:], 0)
+			if end < 0 {
+				return fmt.Errorf("invalid index file")
+			}
+			entry.path = string(data[offset : offset+end])
+			offset += end + 1
+			// entries are padded with NULs to a multiple of 8 bytes
+			for (offset-entryStart)%8 != 0 {
+				offset++
+			}
+		}
+		prevPath = entry.path
+
+		if entry.stage != 0 {
+			repo.conflicts[entry.path] = true
+			continue
+		}
+		repo.index = append(repo.index, &entry)
+		repo.indexPaths[entry.path] = &entry
+	}
+	return nil
+}
+
+// headTree finds the hash of the tree of the commit that HEAD points to. It returns "" for a repo without
+// any commits.
+func (repo *gitRepo) headTree() (string, error) {
+	ref := "HEAD"
+	commit := ""
+	// follow symbolic refs, e.g. "ref: refs/heads/main"
+	for i := 0; i < 5 && commit == ""; i++ {
+		value, err := repo.readRef(ref)
+		if err != nil || value == "" {
+			return "", err
+		}
+		if strings.HasPrefix(value, "ref: ") {
+			ref = strings.TrimSpace(value[len("ref: "):])
+		} else {
+			commit = value
+		}
+	}
+	objType, data, err := repo.readObject(commit)
+	if err != nil {
+		return "", err
+	}
+	if objType != "commit" || !bytes.HasPrefix(data, []byte("tree ")) {
+		return "", fmt.Errorf("HEAD is not a commit")
+	}
+	end := bytes.IndexByte(data, '\n')
+	return string(data[len("tree "):end]), nil
+}
+
+// readRef returns the contents of a loose ref, or its hash from the packed-refs file.
+func (repo *gitRepo) readRef(ref string) (string, error) {
+	dir := repo.commonDir
+	// HEAD and other per-worktree refs live in the worktree's git dir
+	if !strings.HasPrefix(ref, "refs/") || strings.HasPrefix(ref, "refs/bisect/") {
+		dir = repo.gitDir
+	}
+	contents, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref)))
+	if err == nil {
+		return strings.TrimSpace(string(contents)), nil
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	packedRefs, err := os.Open(filepath.Join(repo.commonDir, "packed-refs"))
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	defer packedRefs.Close()
+	scanner := bufio.NewScanner(packedRefs)
+	for scanner.Scan() {
+		hash, name, hasName := strings.Cut(scanner.Text(), " ")
+		if hasName && name == ref {
+			return hash, nil
+		}
+	}
+	return "", nil
+}
+
+// readTree flattens a tree object into a map of every blob (or submodule) under it.
+func (repo *gitRepo) readTree(treeHash string, prefix string, entries map[string]treeEntry) error {
+	objType, data, err := repo.readObject(treeHash)
+	if err != nil {
+		return err
+	}
+	if objType != "tree" {
+		return fmt.Errorf("object %s is not a tree", treeHash)
+	}
+	// each entry is "<octal mode> <name>\0<binary hash>"
+	for len(data) > 0 {
+		space := bytes.IndexByte(data, ' ')
+		nul := bytes.IndexByte(data, 0)
+		if space < 0 || nul < space || nul+1+repo.hashSize > len(data) {
+			return fmt.Errorf("invalid tree %s", treeHash)
+		}
+		mode, err := strconv.ParseUint(string(data[:space]), 8, 32)
+		if err != nil {
+			return err
+		}
+		name := prefix + string(data[space+1:nul])
+		hash := hex.EncodeToString(data[nul+1 : nul+1+repo.hashSize])
+		data = data[nul+1+repo.hashSize:]
+
+		if mode == 040000 {
+			if err := repo.readTree(hash, name+"/", entries); err != nil {
+				return err
+			}
+		} else {
+			entries[name] = treeEntry{uint32(mode), hash}
+		}
+	}
+	return nil
+}
+
+// readObject finds an object by its hex hash, either as a loose object or in a pack file.
+func (repo *gitRepo) readObject(objHash string) (string, []byte, error) {
+	if len(objHash) != repo.hashSize*2 {
+		return "", nil, fmt.Errorf("invalid object name %q", objHash)
+	}
+	objectsDir := filepath.Join(repo.commonDir, "objects")
+	file, err := os.Open(filepath.Join(objectsDir, objHash[:2], objHash[2:]))
+	if err == nil {
+		defer file.Close()
+		return readLooseObject(file)
+	} else if !os.IsNotExist(err) {
+		return "", nil, err
+	}
+
+	if repo.packs == nil {
+		repo.packs, err = openPacks(filepath.Join(objectsDir, "pack"), repo.hashSize)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	rawHash, err := hex.DecodeString(objHash)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, pack := range repo.packs {
+		if offset, found := pack.find(rawHash); found {
+			return pack.readAt(offset, repo)
+		}
+	}
+	return "", nil, fmt.Errorf("object %s not found", objHash)
+}
+
+// a loose object is zlib-compressed, with a header like "blob 1234\0"
+func readLooseObject(r io.Reader) (string, []byte, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return "", nil, err
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return "", nil, err
+	}
+	nul := bytes.IndexByte(data, 0)
+	space := bytes.IndexByte(data, ' ')
+	if nul < 0 || space < 0 || space > nul {
+		return "", nil, fmt.Errorf("invalid object header")
+	}
+	return string(data[:space]), data[nul+1:], nil
+}
+
+// hashBlob computes the object name that a file's contents would have if it were added to the repo.
+func (repo *gitRepo) hashBlob(contents []byte) string {
+	h := repo.newHash()
+	fmt.Fprintf(h, "blob %d\x00", len(contents))
+	h.Write(contents)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// indexRange returns the index entries under a folder, relying on the index being sorted by path.
+func (repo *gitRepo) indexRange(dirPrefix string) []*indexEntry {
+	start := sort.Search(len(repo.index), func(i int) bool {
+		return repo.index[i].path >= dirPrefix
+	})
+	end := start
+	for end < len(repo.index) && strings.HasPrefix(repo.index[end].path, dirPrefix) {
+		end++
+	}
+	return repo.index[start:end]
+}
+
+// readOffsetVarint decodes git's variable-length integers used for offsets, in which each continuation
+// byte also adds 1 to avoid redundant encodings.
+func readOffsetVarint(data []byte) (uint64, int) {
+	if len(data) == 0 {
+		return 0, 0
+	}
+	c := data[0]
+	value := uint64(c & 0x7f)
+	n := 1
+	for c&0x80 != 0 && n < len(data) {
+		c = data[n]
+		n++
+		value = ((value + 1) << 7) | uint64(c&0x7f)
+	}
+	return value, n
+}
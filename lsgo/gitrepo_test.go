@@ -0,0 +1,239 @@
+package lsgo
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testIndexEntry is what's encoded for each entry of a test index file.
+type testIndexEntry struct {
+	path  string
+	mode  uint32
+	size  uint32
+	mtime uint32
+	stage int
+}
+
+// encodeIndex builds a .git/index file in version 2 or 4 of the format, with a made-up hash for each entry.
+func encodeIndex(version uint32, entries []testIndexEntry) []byte {
+	data := []byte("DIRC")
+	data = binary.BigEndian.AppendUint32(data, version)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(entries)))
+	prevPath := ""
+	for _, entry := range entries {
+		start := len(data)
+		stat := make([]uint32, 10)
+		stat[2], stat[3], stat[6], stat[9] = entry.mtime, 500, entry.mode, entry.size
+		for _, field := range stat {
+			data = binary.BigEndian.AppendUint32(data, field)
+		}
+		hash := sha1.Sum([]byte(entry.path))
+		data = append(data, hash[:]...)
+		data = binary.BigEndian.AppendUint16(data, uint16(entry.stage<<12|len(entry.path)))
+		if version == 4 {
+			// strip the part of the previous path that isn't shared
+			common := 0
+			for common < len(prevPath) && common < len(entry.path) && prevPath[common] == entry.path[common] {
+				common++
+			}
+			data = append(data, encodeOffsetVarint(uint64(len(prevPath)-common))...)
+			data = append(data, entry.path[common:]...)
+			data = append(data, 0)
+		} else {
+			data = append(data, entry.path...)
+			data = append(data, 0)
+			for (len(data)-start)%8 != 0 {
+				data = append(data, 0)
+			}
+		}
+		prevPath = entry.path
+	}
+	return data
+}
+
+// encodeOffsetVarint is the opposite of readOffsetVarint.
+func encodeOffsetVarint(value uint64) []byte {
+	encoded := []byte{byte(value & 0x7f)}
+	for value >>= 7; value > 0; value >>= 7 {
+		value--
+		encoded = append([]byte{byte(0x80 | value&0x7f)}, encoded...)
+	}
+	return encoded
+}
+
+func TestReadIndex(t *testing.T) {
+	entries := []testIndexEntry{
+		{"README.md", 0100644, 120, 1700000000, 0},
+		{"conflict.txt", 0100644, 10, 1700000001, 1},
+		{"conflict.txt", 0100644, 11, 1700000002, 2},
+		{"src/main.go", 0100755, 4000, 1700000003, 0},
+		{"src/main_test.go", 0100644, 300, 1700000004, 0},
+		{"vendor", 0160000, 0, 0, 0},
+	}
+	for _, version := range []uint32{2, 3, 4} {
+		gitDir := t.TempDir()
+		writeTestFile(t, filepath.Join(gitDir, "index"), string(encodeIndex(version, entries)))
+		repo := gitRepo{gitDir: gitDir, hashSize: sha1.Size}
+		if err := repo.readIndex(); err != nil {
+			t.Errorf("version %d: unexpected error: %s", version, err)
+			continue
+		}
+
+		paths := []string{}
+		for _, entry := range repo.index {
+			paths = append(paths, entry.path)
+		}
+		if want := []string{"README.md", "src/main.go", "src/main_test.go", "vendor"}; !reflect.DeepEqual(paths, want) {
+			t.Errorf("version %d: paths = %q, want %q", version, paths, want)
+		}
+		if !reflect.DeepEqual(repo.conflicts, map[string]bool{"conflict.txt": true}) {
+			t.Errorf("version %d: conflicts = %v", version, repo.conflicts)
+		}
+		mainHash := sha1.Sum([]byte("src/main.go"))
+		main := repo.indexPaths["src/main.go"]
+		want := indexEntry{"src/main.go", 0100755, hex.EncodeToString(mainHash[:]), 4000, 1700000003, 500, 0}
+		if main == nil || *main != want {
+			t.Errorf("version %d: src/main.go = %+v, want %+v", version, main, want)
+		}
+		if inDir := repo.indexRange("src/"); len(inDir) != 2 {
+			t.Errorf("version %d: indexRange(src/) has %d entries, want 2", version, len(inDir))
+		}
+	}
+}
+
+func TestReadIndexErrors(t *testing.T) {
+	entries := []testIndexEntry{{"a.txt", 0100644, 1, 1, 0}, {"b.txt", 0100644, 1, 1, 0}}
+	valid := encodeIndex(2, entries)
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{"empty", nil, "invalid index file"},
+		{"bad signature", append([]byte("DIRX"), valid[4:]...), "invalid index file"},
+		{"old version", append([]byte("DIRC\x00\x00\x00\x01"), valid[8:]...), "unsupported index version 1"},
+		{"truncated", valid[:len(valid)-40], "truncated index file"},
+		{"missing NUL", valid[:12+62+5], "invalid index file"},
+	}
+	for _, test := range tests {
+		gitDir := t.TempDir()
+		writeTestFile(t, filepath.Join(gitDir, "index"), string(test.data))
+		repo := gitRepo{gitDir: gitDir, hashSize: sha1.Size}
+		if err := repo.readIndex(); err == nil || err.Error() != test.err {
+			t.Errorf("%s: error = %v, want %q", test.name, err, test.err)
+		}
+	}
+
+	// a repo without an index, e.g. right after `git init`, just has no entries
+	repo := gitRepo{gitDir: t.TempDir(), hashSize: sha1.Size}
+	if err := repo.readIndex(); err != nil || len(repo.index) != 0 {
+		t.Errorf("missing index: %d entries, error %v", len(repo.index), err)
+	}
+}
+
+func TestReadOffsetVarint(t *testing.T) {
+	tests := []struct {
+		data  []byte
+		value uint64
+		n     int
+	}{
+		{nil, 0, 0},
+		{[]byte{0x00}, 0, 1},
+		{[]byte{0x7f}, 127, 1},
+		{[]byte{0x80, 0x00}, 128, 2},
+		{[]byte{0x80, 0x7f}, 255, 2},
+		{[]byte{0x81, 0x00, 0xff}, 256, 2},
+		{[]byte{0xff, 0x7f}, 16511, 2},
+		{[]byte{0x80, 0x80, 0x00}, 16512, 3},
+	}
+	for _, test := range tests {
+		value, n := readOffsetVarint(test.data)
+		if value != test.value || n != test.n {
+			t.Errorf("readOffsetVarint(%x) = %d, %d, want %d, %d", test.data, value, n, test.value, test.n)
+		}
+		if test.n > 0 && !bytes.Equal(encodeOffsetVarint(test.value), test.data[:test.n]) {
+			t.Errorf("encodeOffsetVarint(%d) = %x, want %x", test.value, encodeOffsetVarint(test.value), test.data[:test.n])
+		}
+	}
+}
+
+// zlibCompress compresses data like git does for objects.
+func zlibCompress(data []byte) []byte {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write(data)
+	zw.Close()
+	return compressed.Bytes()
+}
+
+func TestReadLooseObject(t *testing.T) {
+	tests := []struct {
+		raw     string
+		objType string
+		data    string
+		err     string
+	}{
+		{"blob 5\x00hello", "blob", "hello", ""},
+		{"tree 0\x00", "tree", "", ""},
+		{"commit 3\x00a b", "commit", "a b", ""},
+		{"blob5\x00hello", "", "", "invalid object header"},
+		{"blob 5", "", "", "invalid object header"},
+	}
+	for _, test := range tests {
+		objType, data, err := readLooseObject(bytes.NewReader(zlibCompress([]byte(test.raw))))
+		errStr := ""
+		if err != nil {
+			errStr = err.Error()
+		}
+		if objType != test.objType || string(data) != test.data || errStr != test.err {
+			t.Errorf("readLooseObject(%q) = %q, %q, %q, want %q, %q, %q", test.raw, objType, data, errStr,
+				test.objType, test.data, test.err)
+		}
+	}
+
+	if _, _, err := readLooseObject(bytes.NewReader([]byte("not zlib"))); err == nil {
+		t.Errorf("readLooseObject of data that isn't compressed didn't give an error")
+	}
+}
+
+func TestGitConfigValue(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config")
+	writeTestFile(t, configFile, `# a comment
+[core]
+	bare = false
+	excludesFile = "~/.gitignore_global"
+; another comment
+[Extensions]
+	objectFormat = sha256
+[remote "origin"]
+	url = https://example.com/repo.git
+[core]
+	autocrlf=input
+`)
+	tests := []struct {
+		section string
+		key     string
+		value   string
+	}{
+		{"core", "bare", "false"},
+		{"core", "excludesfile", "~/.gitignore_global"},
+		{"core", "autocrlf", "input"},
+		{"extensions", "objectformat", "sha256"},
+		{"core", "missing", ""},
+		{"user", "name", ""},
+	}
+	for _, test := range tests {
+		if value := gitConfigValue(configFile, test.section, test.key); value != test.value {
+			t.Errorf("gitConfigValue(%s.%s) = %q, want %q", test.section, test.key, value, test.value)
+		}
+	}
+	if value := gitConfigValue(filepath.Join(t.TempDir(), "missing"), "core", "bare"); value != "" {
+		t.Errorf("gitConfigValue of a missing file = %q", value)
+	}
+}
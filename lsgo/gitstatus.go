@@ -0,0 +1,229 @@
+package lsgo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Each status is 2 characters, like `git status --short`: the first for the changes staged in the index
+// and the second for the changes in the working tree. A space means no change.
+const (
+	gitClean      = "  "
+	gitUntracked  = "??"
+	gitIgnored    = "!!"
+	gitConflicted = "UU"
+)
+
+// When rolling up the statuses in a folder, the characters earlier in this list win.
+const gitStatusPriority = "UMDA?!"
+
+// gitStatusForItem returns the status of an item in a folder, or "" if the folder isn't in a repo.
+func (l *Lister) gitStatusForItem(absDir string, item *Entry) string {
+	repo := l.findGitRepo(absDir)
+	if repo == nil {
+		return ""
+	}
+	relPath, err := filepath.Rel(repo.root, filepath.Join(absDir, item.Info.Name()))
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return ""
+	}
+	relPath = filepath.ToSlash(relPath)
+	if relPath == ".git" {
+		return gitClean
+	}
+
+	if status, isCached := repo.statusCache[relPath]; isCached {
+		return status
+	}
+	var status string
+	// a submodule is a folder, but it's tracked like a file
+	if item.Info.IsDir() && repo.indexPaths[relPath] == nil {
+		status = repo.dirStatus(relPath)
+	} else {
+		status = repo.fileStatus(relPath, item.Info)
+	}
+	repo.statusCache[relPath] = status
+	return status
+}
+
+func (repo *gitRepo) fileStatus(relPath string, info os.FileInfo) string {
+	if repo.conflicts[relPath] {
+		return gitConflicted
+	}
+	headEntry, inHead := repo.head[relPath]
+	entry := repo.indexPaths[relPath]
+	if entry == nil {
+		if inHead {
+			// removed from the index with `git rm --cached`, but still on disk
+			return "D?"
+		}
+		if repo.ignore.isIgnored(relPath, false) {
+			return gitIgnored
+		}
+		return gitUntracked
+	}
+
+	staged := byte(' ')
+	if !inHead {
+		staged = 'A'
+	} else if headEntry.hash != entry.hash || headEntry.mode != entry.mode {
+		staged = 'M'
+	}
+	unstaged := byte(' ')
+	if info == nil {
+		unstaged = 'D'
+	} else if repo.isModified(entry, info) {
+		unstaged = 'M'
+	}
+	return string([]byte{staged, unstaged})
+}
+
+// isModified compares a file in the working tree to its entry in the index. The contents only need to be
+// hashed if the size or modification time have changed since the file was staged.
+func (repo *gitRepo) isModified(entry *indexEntry, info os.FileInfo) bool {
+	const (
+		typeMask    = 0170000
+		typeSymlink = 0120000
+		typeGitlink = 0160000
+	)
+	entryType := entry.mode & typeMask
+	if entryType == typeGitlink {
+		// submodules are repos of their own
+		return false
+	}
+	isSymlink := info.Mode()&os.ModeSymlink != 0
+	if isSymlink != (entryType == typeSymlink) || (!isSymlink && !info.Mode().IsRegular()) {
+		return true
+	}
+	if !isSymlink && (entry.mode&0100 != 0) != (info.Mode()&0100 != 0) {
+		return true
+	}
+
+	modTime := info.ModTime()
+	if uint32(info.Size()) == entry.size && uint32(modTime.Unix()) == entry.mtimeSec &&
+		uint32(modTime.Nanosecond()) == entry.mtimeNs {
+		return false
+	}
+
+	absPath := filepath.Join(repo.root, filepath.FromSlash(entry.path))
+	var contents []byte
+	var err error
+	if isSymlink {
+		var target string
+		target, err = os.Readlink(absPath)
+		contents = []byte(target)
+	} else {
+		contents, err = os.ReadFile(absPath)
+	}
+	if err != nil {
+		return true
+	}
+	return repo.hashBlob(contents) != entry.hash
+}
+
+// dirStatus rolls up the statuses of everything inside a folder.
+func (repo *gitRepo) dirStatus(relPath string) string {
+	if repo.ignore.isIgnored(relPath, true) {
+		return gitIgnored
+	}
+	prefix := relPath + "/"
+	statuses := []string{}
+	for _, entry := range repo.indexRange(prefix) {
+		info, err := os.Lstat(filepath.Join(repo.root, filepath.FromSlash(entry.path)))
+		if err != nil {
+			info = nil
+		}
+		statuses = append(statuses, repo.fileStatus(entry.path, info))
+	}
+	for conflict := range repo.conflicts {
+		if strings.HasPrefix(conflict, prefix) {
+			statuses = append(statuses, gitConflicted)
+		}
+	}
+	for headPath := range repo.head {
+		if strings.HasPrefix(headPath, prefix) && repo.indexPaths[headPath] == nil && !repo.conflicts[headPath] {
+			statuses = append(statuses, "D ")
+		}
+	}
+
+	if len(statuses) == 0 {
+		// nothing in here is tracked, so the whole folder is untracked unless it's empty
+		if repo.hasUntracked(relPath) {
+			return gitUntracked
+		}
+		return gitClean
+	}
+	status := rollUpGitStatus(statuses)
+	// looking for untracked files is the slowest part, so skip it if there's something more important
+	if status[1] == ' ' && repo.hasUntracked(relPath) {
+		status = rollUpGitStatus([]string{status, " ?"})
+	}
+	return status
+}
+
+func rollUpGitStatus(statuses []string) string {
+	rolledUp := []byte(gitClean)
+	for _, status := range statuses {
+		for i := 0; i < 2; i++ {
+			current := strings.IndexByte(gitStatusPriority, rolledUp[i])
+			next := strings.IndexByte(gitStatusPriority, status[i])
+			if next >= 0 && (current < 0 || next < current) {
+				rolledUp[i] = status[i]
+			}
+		}
+	}
+	return string(rolledUp)
+}
+
+// hasUntracked searches a folder for any file that is neither tracked nor ignored.
+func (repo *gitRepo) hasUntracked(relPath string) bool {
+	entries, err := os.ReadDir(filepath.Join(repo.root, filepath.FromSlash(relPath)))
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		childPath := relPath + "/" + entry.Name()
+		if entry.IsDir() {
+			if entry.Name() == ".git" || repo.ignore.isIgnored(childPath, true) {
+				continue
+			}
+			if _, isSubmodule := repo.indexPaths[childPath]; isSubmodule {
+				continue
+			}
+			if repo.hasUntracked(childPath) {
+				return true
+			}
+		} else if repo.indexPaths[childPath] == nil && !repo.conflicts[childPath] &&
+			!repo.ignore.isIgnored(childPath, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// gitStatusString colors each of the 2 status characters, showing "-" for unchanged.
+func gitStatusString(status string) string {
+	if status == "" {
+		return ""
+	}
+	colors := ConfigColor["git"]
+	colored := []string{}
+	for i := 0; i < 2; i++ {
+		switch status[i] {
+		case ' ':
+			colored = append(colored, colors["clean"]+"-")
+		case 'A', '?':
+			colored = append(colored, colors["new"]+string(status[i]))
+		case 'M':
+			colored = append(colored, colors["modified"]+"M")
+		case 'D':
+			colored = append(colored, colors["deleted"]+"D")
+		case 'U':
+			colored = append(colored, colors["conflicted"]+"U")
+		case '!':
+			colored = append(colored, colors["ignored"]+"!")
+		}
+	}
+	return strings.Join(colored, "")
+}
@@ -0,0 +1,83 @@
+package lsgo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// gitTestRepo makes a repo with the git command, which the test is skipped without.
+func gitTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "clean.txt"), "clean\n")
+	writeTestFile(t, filepath.Join(dir, "modified.txt"), "before\n")
+	writeTestFile(t, filepath.Join(dir, "sub", "tracked.txt"), "tracked\n")
+	runGit(t, dir, "init", "-q")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "first")
+
+	writeTestFile(t, filepath.Join(dir, "modified.txt"), "after, which is longer\n")
+	writeTestFile(t, filepath.Join(dir, "staged.txt"), "new\n")
+	runGit(t, dir, "add", "staged.txt")
+	writeTestFile(t, filepath.Join(dir, "sub", "untracked.txt"), "")
+	writeTestFile(t, filepath.Join(dir, "new", "file"), "")
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %s\n%s", args, err, out)
+	}
+}
+
+func TestGitStatus(t *testing.T) {
+	dir := gitTestRepo(t)
+	listing := listTestDir(t, Options{Git: true}, dir)
+	expected := map[string]string{
+		"clean":    gitClean,
+		"modified": " M",
+		"staged":   "A ",
+		// a folder with something untracked in it, and one with nothing tracked
+		"sub": " ?",
+		"new": gitUntracked,
+	}
+	for _, entry := range listing.Entries() {
+		if want := expected[entry.Basename]; entry.GitStatus != want {
+			t.Errorf("the status of %s is %q, want %q", entry.Basename, entry.GitStatus, want)
+		}
+	}
+}
+
+func TestGitStatusOfFileArguments(t *testing.T) {
+	dir := gitTestRepo(t)
+	chdir(t, filepath.Join(dir, "sub"))
+
+	// the files are looked up from the folder they're in, not the working directory
+	paths := []string{"../modified.txt", "../clean.txt", "untracked.txt"}
+	infos := make([]os.FileInfo, len(paths))
+	for i, pathStr := range paths {
+		var err error
+		if infos[i], err = os.Stat(pathStr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	listing := NewLister(Options{Git: true}).ListFiles(paths, infos)
+	expected := map[string]string{
+		"clean":     gitClean,
+		"modified":  " M",
+		"untracked": gitUntracked,
+	}
+	for _, entry := range listing.Files {
+		if want := expected[entry.Basename]; entry.GitStatus != want {
+			t.Errorf("the status of %s is %q, want %q", entry.Basename, entry.GitStatus, want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package lsgo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/acarl005/stripansi"
+)
+
+// Matches the OSC 8 sequences that start and end a hyperlink, which stripansi doesn't know about.
+var hyperlinkSequence = regexp.MustCompile("\x1b]8;[^\x1b\x07]*(?:\x1b\\\\|\x07)")
+
+// visibleWidth counts the characters of a string that take up space in the terminal.
+func visibleWidth(str string) int {
+	return utf8.RuneCountInString(stripansi.Strip(hyperlinkSequence.ReplaceAllString(str, "")))
+}
+
+// hyperlink makes some text a link to a file, with an OSC 8 sequence, if RenderOptions.Hyperlink is set.
+// Terminals that don't support them just show the text.
+func (r *Renderer) hyperlink(absPath string, text string) string {
+	if !r.opts.Hyperlink || absPath == "" {
+		return text
+	}
+	url := "file://" + r.host + fileURLPath(absPath)
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// fileURLPath escapes a path for a file:// URL, leaving the slashes alone.
+func fileURLPath(absPath string) string {
+	var escaped strings.Builder
+	// Windows paths like C:\Users become /C:/Users
+	urlPath := filepath.ToSlash(absPath)
+	if !strings.HasPrefix(urlPath, "/") {
+		urlPath = "/" + urlPath
+	}
+	for _, char := range []byte(urlPath) {
+		if isURLSafe(char) {
+			escaped.WriteByte(char)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", char)
+		}
+	}
+	return escaped.String()
+}
+
+func isURLSafe(char byte) bool {
+	return 'a' <= char && char <= 'z' || 'A' <= char && char <= 'Z' || '0' <= char && char <= '9' ||
+		strings.IndexByte("/-._~!$&'()*+,;=:@", char) >= 0
+}
+
+// printGrid arranges the strings in columns from top to bottom, like `ls`, as wide as the terminal allows.
+func (r *Renderer) printGrid(strs []string, margin int) {
+	// the longest string decides the width of every column
+	maxWidth := 0
+	widths := make([]int, len(strs))
+	for i, str := range strs {
+		widths[i] = visibleWidth(str)
+		maxWidth = max(maxWidth, widths[i])
+	}
+
+	numCols := (r.width() + margin) / (maxWidth + margin)
+	if numCols <= 1 {
+		for _, str := range strs {
+			fmt.Fprintln(r.out, str)
+		}
+		return
+	}
+	numRows := (len(strs) + numCols - 1) / numCols
+
+	for y := 0; y < numRows; y++ {
+		line := ""
+		for x := 0; x < numCols; x++ {
+			// the strings go down each column before moving to the next one
+			i := y + numRows*x
+			if i >= len(strs) {
+				break
+			}
+			if x > 0 {
+				line += strings.Repeat(" ", maxWidth-widths[i-numRows]+margin)
+			}
+			line += strs[i]
+		}
+		fmt.Fprintln(r.out, line)
+	}
+}
+
+// width is how wide the grid can be, from RenderOptions.Width or else the terminal.
+func (r *Renderer) width() int {
+	if r.opts.Width > 0 {
+		return r.opts.Width
+	}
+	if file, isFile := r.out.(*os.File); isFile {
+		return termWidth(file)
+	}
+	return termWidth(os.Stdout)
+}
+
+// fallbackTermWidth is used when the terminal can't be asked how wide it is: $COLUMNS, or 80.
+func fallbackTermWidth() int {
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 80
+}
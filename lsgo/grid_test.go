@@ -0,0 +1,94 @@
+package lsgo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gridTestDir makes a folder of files with names of different lengths.
+func gridTestDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"aa", "bbbb", "c d", "eeeeee", "f"} {
+		writeTestFile(t, filepath.Join(dir, name), "")
+	}
+	writeTestFile(t, filepath.Join(dir, "sub", "file"), "")
+	return dir
+}
+
+func TestGridWidth(t *testing.T) {
+	listing := listTestDir(t, Options{}, gridTestDir(t))
+	tests := []struct {
+		width int
+		grid  string
+	}{
+		{80, " sub      aa       bbbb     c d      eeeeee   f\n"},
+		{20, " sub      c d\n aa       eeeeee\n bbbb     f\n"},
+		{5, " sub \n aa\n bbbb\n c d\n eeeeee\n f\n"},
+	}
+	for _, test := range tests {
+		grid := renderTestListing(t, RenderOptions{Grid: true, Width: test.width}, listing)
+		if grid != test.grid {
+			t.Errorf("%d columns wide:\n%q\nwant\n%q", test.width, grid, test.grid)
+		}
+	}
+}
+
+func TestHyperlinks(t *testing.T) {
+	dir := gridTestDir(t)
+	listing := listTestDir(t, Options{}, dir)
+	host, _ := os.Hostname()
+
+	for _, opts := range []RenderOptions{{Grid: true, Width: 20}, {Perms: true}} {
+		plain := renderTestListing(t, opts, listing)
+		opts.Hyperlink = true
+		linked := renderTestListing(t, opts, listing)
+
+		// the links don't take up any space, so the layout is the same
+		if stripped := hyperlinkSequence.ReplaceAllString(linked, ""); stripped != plain {
+			t.Errorf("%+v: the links changed the layout:\n%q\nwant\n%q", opts, stripped, plain)
+		}
+		for _, name := range []string{"sub", "c d", "eeeeee"} {
+			link := "\x1b]8;;file://" + host + fileURLPath(filepath.Join(dir, name)) + "\x1b\\"
+			if !strings.Contains(linked, link) {
+				t.Errorf("%+v: no link to %s in %q", opts, name, linked)
+			}
+		}
+	}
+}
+
+func TestFileURLPath(t *testing.T) {
+	tests := []struct {
+		path string
+		url  string
+	}{
+		{"/tmp/file.txt", "/tmp/file.txt"},
+		{"/tmp/c d", "/tmp/c%20d"},
+		{"/tmp/100%/#1?", "/tmp/100%25/%231%3F"},
+		{"/tmp/café", "/tmp/caf%C3%A9"},
+	}
+	for _, test := range tests {
+		if url := fileURLPath(test.path); url != test.url {
+			t.Errorf("fileURLPath(%q) = %q, want %q", test.path, url, test.url)
+		}
+	}
+}
+
+func TestVisibleWidth(t *testing.T) {
+	tests := []struct {
+		str   string
+		width int
+	}{
+		{"plain", 5},
+		{Bold + NamedFg(Red) + "red" + Reset, 3},
+		{"\x1b]8;;file:///tmp/x\x1b\\x\x1b]8;;\x1b\\", 1},
+		{"\x1b]8;;file:///tmp/x\x07café\x1b]8;;\x07", 4},
+	}
+	for _, test := range tests {
+		if width := visibleWidth(test.str); width != test.width {
+			t.Errorf("visibleWidth(%q) = %d, want %d", test.str, width, test.width)
+		}
+	}
+}
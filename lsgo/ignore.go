@@ -0,0 +1,59 @@
+package lsgo
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFor returns the ignore rules for listing a folder, or nil if neither Options.GitIgnore nor
+// Options.IgnoreFiles is set. The rules set up for a folder are reused for the folders inside it, so that
+// the patterns in Options.IgnoreFiles stay relative to the folder that was listed first.
+func (l *Lister) ignoreFor(absDir string) *ignoreMatcher {
+	if !l.opts.GitIgnore && len(l.opts.IgnoreFiles) == 0 {
+		return nil
+	}
+	if l.ignore != nil && (absDir == l.ignoreBase || strings.HasPrefix(absDir, l.ignoreBase+string(filepath.Separator))) {
+		return l.ignore
+	}
+	l.ignore = l.newIgnoreMatcher(absDir)
+	l.ignoreBase = absDir
+	return l.ignore
+}
+
+// newIgnoreMatcher builds the ignore rules for listing a folder. With Options.GitIgnore, the rules are rooted
+// at the top of the repo so that the .gitignore files in the parent folders still apply. Patterns in
+// Options.IgnoreFiles are relative to the listed folder.
+func (l *Lister) newIgnoreMatcher(absDir string) *ignoreMatcher {
+	var matcher *ignoreMatcher
+	root := absDir
+	if l.opts.GitIgnore {
+		if repo := l.findGitRepo(absDir); repo != nil {
+			root = repo.root
+			matcher = newIgnoreMatcher(root, []string{".gitignore", ".lsgoignore"})
+			matcher.addGlobalFile(globalExcludesFile(filepath.Join(repo.commonDir, "config")), "")
+			matcher.addGlobalFile(filepath.Join(repo.commonDir, "info", "exclude"), "")
+		} else {
+			matcher = newIgnoreMatcher(root, []string{".lsgoignore"})
+		}
+	} else {
+		matcher = newIgnoreMatcher(root, nil)
+	}
+
+	base, err := filepath.Rel(root, absDir)
+	if err != nil || base == "." {
+		base = ""
+	}
+	for _, ignoreFile := range l.opts.IgnoreFiles {
+		matcher.addGlobalFile(ignoreFile, filepath.ToSlash(base))
+	}
+	return matcher
+}
+
+// isIgnoredBy checks an item in a folder against a set of ignore rules.
+func isIgnoredBy(matcher *ignoreMatcher, absDir string, name string, isDir bool) bool {
+	relPath, err := filepath.Rel(matcher.root, filepath.Join(absDir, name))
+	if err != nil {
+		return false
+	}
+	return matcher.isIgnored(filepath.ToSlash(relPath), isDir)
+}
@@ -0,0 +1,162 @@
+package lsgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/acarl005/stripansi"
+)
+
+// JSONListing is the object emitted for each folder (or group of file arguments) with --json.
+// One listing is written per line, so the output can be streamed and parsed one line at a time.
+type JSONListing struct {
+	// the folder as it was passed, or "" for the file arguments
+	Path    string       `json:"path"`
+	Error   string       `json:"error,omitempty"`
+	Entries []*JSONEntry `json:"entries"`
+}
+
+// JSONEntry is the machine-readable form of an Entry.
+type JSONEntry struct {
+	Name     string     `json:"name"`
+	Path     string     `json:"path"`
+	Basename string     `json:"basename"`
+	Ext      string     `json:"ext"`
+	Type     string     `json:"type"`
+	Mode     string     `json:"mode"`
+	Perms    string     `json:"perms"`
+	Size     int64      `json:"size"`
+	DiskSize int64      `json:"disk_size"`
+	ModTime  time.Time  `json:"mtime"`
+	Atime    *time.Time `json:"atime,omitempty"`
+	Ctime    *time.Time `json:"ctime,omitempty"`
+	Btime    *time.Time `json:"btime,omitempty"`
+	Owner    string     `json:"owner"`
+	Group    string     `json:"group"`
+	Inode    uint64     `json:"inode,omitempty"`
+	Links    uint64     `json:"links,omitempty"`
+	Xattrs   []string   `json:"xattrs,omitempty"`
+	ACL      []string   `json:"acl,omitempty"`
+	Link     *JSONLink  `json:"link,omitempty"`
+	Git      string     `json:"git,omitempty"`
+}
+
+// JSONLink describes the target of a symlink.
+type JSONLink struct {
+	Target string `json:"target"`
+	Broken bool   `json:"broken"`
+}
+
+func jsonEntry(item *Entry) *JSONEntry {
+	info := item.Info
+	entry := JSONEntry{
+		Name:     info.Name(),
+		Path:     item.Path,
+		Basename: item.Basename,
+		Ext:      item.Ext,
+		Type:     fileType(info.Mode()),
+		Mode:     modeString(item),
+		Perms:    fmt.Sprintf("%04o", unixPerms(info.Mode())),
+		Size:     item.Size,
+		DiskSize: item.DiskSize,
+		ModTime:  info.ModTime(),
+		Owner:    item.Owner,
+		Group:    item.Group,
+		Inode:    item.Inode,
+		Links:    item.Links,
+		Xattrs:   item.Xattrs,
+		Git:      item.GitStatus,
+	}
+	// the other times are left out if the platform doesn't have them
+	for _, field := range []struct {
+		time time.Time
+		dest **time.Time
+	}{{item.AccessTime, &entry.Atime}, {item.ChangeTime, &entry.Ctime}, {item.BirthTime, &entry.Btime}} {
+		if !field.time.IsZero() {
+			fieldTime := field.time
+			*field.dest = &fieldTime
+		}
+	}
+	for _, acl := range item.ACL {
+		entry.ACL = append(entry.ACL, acl.String())
+	}
+	if item.Link != nil {
+		entry.Link = &JSONLink{
+			Target: item.Link.Path,
+			Broken: item.Link.Broken,
+		}
+	}
+	return &entry
+}
+
+// fileType names the kind of file as far as the OS is concerned
+func fileType(mode os.FileMode) string {
+	switch {
+	case mode&os.ModeDir != 0:
+		return "directory"
+	case mode&os.ModeSymlink != 0:
+		return "symlink"
+	case mode&os.ModeCharDevice != 0:
+		return "char-device"
+	case mode&os.ModeDevice != 0:
+		return "block-device"
+	case mode&os.ModeNamedPipe != 0:
+		return "pipe"
+	case mode&os.ModeSocket != 0:
+		return "socket"
+	}
+	return "file"
+}
+
+// modeString is the permissions like in `ls -l`, e.g. "drwxr-sr-x+", without the colors.
+func modeString(item *Entry) string {
+	mode := item.Info.Mode()
+	perms := rwxString(mode, 2, "") + rwxString(mode, 1, "") + rwxString(mode, 0, "") + attrIndicator(item)
+	return fileTypeChar(mode) + strings.TrimRight(stripansi.Strip(perms), " ")
+}
+
+// unixPerms puts the setuid, setgid, and sticky bits back where chmod has them, since os.FileMode keeps them
+// apart from the permission bits.
+func unixPerms(mode os.FileMode) os.FileMode {
+	perms := mode.Perm()
+	if mode&os.ModeSetuid != 0 {
+		perms |= 04000
+	}
+	if mode&os.ModeSetgid != 0 {
+		perms |= 02000
+	}
+	if mode&os.ModeSticky != 0 {
+		perms |= 01000
+	}
+	return perms
+}
+
+func (r *Renderer) printJSONListing(pathStr string, items []*displayItem) {
+	listing := JSONListing{
+		Path:    pathStr,
+		Entries: make([]*JSONEntry, 0, len(items)),
+	}
+	for _, item := range items {
+		listing.Entries = append(listing.Entries, jsonEntry(item.Entry))
+	}
+	r.writeJSON(&listing)
+}
+
+func (r *Renderer) printJSONError(err error, pathStr string) {
+	r.writeJSON(&JSONListing{
+		Path:    pathStr,
+		Error:   err.Error(),
+		Entries: []*JSONEntry{},
+	})
+}
+
+func (r *Renderer) writeJSON(listing *JSONListing) {
+	encoder := json.NewEncoder(r.out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(listing); err != nil && r.err == nil {
+		r.err = err
+	}
+}
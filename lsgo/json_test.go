@@ -0,0 +1,117 @@
+package lsgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// decodeJSONListings reads the listings written by a Renderer, one per line.
+func decodeJSONListings(t *testing.T, out string) []JSONListing {
+	t.Helper()
+	listings := []JSONListing{}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		var listing JSONListing
+		if err := json.Unmarshal([]byte(line), &listing); err != nil {
+			t.Fatalf("%s: %q", err, line)
+		}
+		listings = append(listings, listing)
+	}
+	return listings
+}
+
+func TestJSONListing(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "main.go"), "package main\n")
+	writeTestFile(t, filepath.Join(dir, "sub", "file"), "")
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(filepath.Join(dir, "main.go"), 0o640); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink("missing", filepath.Join(dir, "broken")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	listing := listTestDir(t, Options{Owners: true}, dir)
+	listings := decodeJSONListings(t, renderTestListing(t, RenderOptions{JSON: true}, listing))
+	if len(listings) != 1 {
+		t.Fatalf("expected one listing, got %d", len(listings))
+	}
+	if listings[0].Path != dir || listings[0].Error != "" {
+		t.Errorf("expected the listing of %s, got %+v", dir, listings[0])
+	}
+
+	entries := map[string]*JSONEntry{}
+	for _, entry := range listings[0].Entries {
+		entries[entry.Name] = entry
+	}
+	if sub := entries["sub"]; sub == nil || sub.Type != "directory" || sub.Mode[0] != 'd' ||
+		sub.Path != filepath.Join(dir, "sub") {
+		t.Errorf("sub: %+v", sub)
+	}
+	goFile := entries["main.go"]
+	if goFile == nil || goFile.Type != "file" || goFile.Basename != "main" || goFile.Ext != "go" ||
+		goFile.Size != 13 {
+		t.Fatalf("main.go: %+v", goFile)
+	}
+	if goFile.ModTime.IsZero() || goFile.Owner == "" {
+		t.Errorf("main.go is missing its time or owner: %+v", goFile)
+	}
+	if runtime.GOOS != "windows" {
+		if goFile.Mode != "-rw-r-----" || goFile.Perms != "0640" {
+			t.Errorf("main.go has mode %s and perms %s", goFile.Mode, goFile.Perms)
+		}
+		if broken := entries["broken"]; broken == nil || broken.Type != "symlink" || broken.Link == nil ||
+			broken.Link.Target != "missing" || !broken.Link.Broken {
+			t.Errorf("broken: %+v", broken)
+		}
+	}
+}
+
+func TestJSONEmptyAndErrorListings(t *testing.T) {
+	GenerateColors(false)
+	var out bytes.Buffer
+	r := NewRenderer(&out, RenderOptions{JSON: true})
+	r.Listing(listTestDir(t, Options{}, t.TempDir()))
+	r.ErrorHeader(errors.New("permission denied"), "secret")
+	r.FolderHeader("ignored")
+	r.Separator()
+
+	listings := decodeJSONListings(t, out.String())
+	if len(listings) != 2 {
+		t.Fatalf("expected 2 listings in %q", out.String())
+	}
+	// the entries are always an array, so scripts can loop over them
+	if listings[0].Entries == nil || len(listings[0].Entries) != 0 || listings[0].Error != "" {
+		t.Errorf("empty folder: %+v", listings[0])
+	}
+	if listings[1].Path != "secret" || listings[1].Error != "permission denied" || listings[1].Entries == nil {
+		t.Errorf("error listing: %+v", listings[1])
+	}
+	if !strings.Contains(out.String(), `"entries":[]`) {
+		t.Errorf("expected empty arrays of entries in %q", out.String())
+	}
+}
+
+func TestUnixPerms(t *testing.T) {
+	tests := []struct {
+		mode  os.FileMode
+		perms os.FileMode
+	}{
+		{0o755, 0o755},
+		{os.ModeDir | 0o750, 0o750},
+		{os.ModeSetuid | 0o755, 0o4755},
+		{os.ModeSetgid | 0o755, 0o2755},
+		{os.ModeDir | os.ModeSticky | 0o777, 0o1777},
+	}
+	for _, test := range tests {
+		if perms := unixPerms(test.mode); perms != test.perms {
+			t.Errorf("unixPerms(%s) = %04o, want %04o", test.mode, perms, test.perms)
+		}
+	}
+}
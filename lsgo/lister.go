@@ -0,0 +1,545 @@
+// Package lsgo is the engine behind the ls-go command. A Lister reads folders into sorted entries, and a
+// Renderer prints them with the colors and icons of ls-go, so that other tools can embed either one.
+package lsgo
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options controls which items a Lister reads and how they are sorted. The zero value lists the items that
+// aren't hidden, sorted by name.
+type Options struct {
+	// include dotfiles
+	All bool
+	// only list folders, or only list files
+	DirsOnly  bool
+	FilesOnly bool
+	// only list the items whose names match
+	Find *regexp.Regexp
+	// the fields to sort by, most important first, which are then sorted by name
+	Sort []SortKey
+	// whether folders are listed before the files, after them, or mixed in with them
+	Group Grouping
+	// which of the timestamps is shown and sorted by
+	TimeField TimeField
+	// show symlink targets as relative paths if they're shorter than the absolute path
+	LinkRel bool
+	// look up the names of the owner and group of each item
+	Owners bool
+	// show the owner and group as numeric ids instead of looking up their names
+	NumericIDs bool
+	// look up the inode number and hard link count of each item, which needs each file to be opened on
+	// Windows
+	FileIDs bool
+	// read the names of the extended attributes of each item
+	Xattrs bool
+	// read the values of the extended attributes too, which implies Xattrs
+	XattrValues bool
+	// decode the POSIX ACLs of each item, which implies Xattrs
+	ACLs bool
+	// read the start of the files that aren't recognized by their name, to guess their type from a magic
+	// number or a shebang line
+	Sniff bool
+	// add up the sizes of everything inside each folder, like `du`. With Walk, this reads everything below
+	// each folder again, once for every level it's listed at
+	DirSize bool
+	// look up the git status of each item
+	Git bool
+	// hide the items ignored by git or by .lsgoignore files
+	GitIgnore bool
+	// hide the items matching the patterns in these gitignore-style files
+	IgnoreFiles []string
+	// how many folders and items are read at the same time, or 0 for the default
+	Workers int
+}
+
+// Grouping is where the folders go in a listing.
+type Grouping int
+
+const (
+	// folders before files, each sorted separately
+	GroupDirsFirst Grouping = iota
+	// folders after files, each sorted separately
+	GroupDirsLast
+	// folders and files sorted together
+	GroupNone
+)
+
+// TimeField is one of the timestamps of a file.
+type TimeField int
+
+const (
+	// when the contents last changed
+	TimeModified TimeField = iota
+	// when the contents were last read
+	TimeAccessed
+	// when the contents or the metadata, like the permissions, last changed
+	TimeChanged
+	// when the file was created, if the platform and filesystem keep track of it
+	TimeCreated
+)
+
+// Entry is an item in a folder along with everything the Lister found out about it.
+type Entry struct {
+	Info os.FileInfo
+	// the absolute path of the item
+	Path     string
+	Basename string
+	Ext      string
+	// the apparent size and the space taken up on disk, which are the totals of everything inside a
+	// folder when Options.DirSize is set
+	Size     int64
+	DiskSize int64
+	// the space taken up on disk by the item itself, which isn't replaced by Options.DirSize
+	OwnDiskSize int64
+	// the timestamps besides Info.ModTime(), which are zero if the platform doesn't have them. The creation
+	// time is only looked up when it's the Options.TimeField.
+	AccessTime time.Time
+	ChangeTime time.Time
+	BirthTime  time.Time
+	// the timestamp chosen by Options.TimeField
+	Time time.Time
+	// where a symlink points, or nil if the item isn't a symlink
+	Link *LinkInfo
+	// only set when Options.Owners is
+	Owner string
+	Group string
+	// the inode number, or the file index on Windows, and the number of hard links, only set when
+	// Options.FileIDs is
+	Inode uint64
+	Links uint64
+	// the names of the extended attributes, only set when Options.Xattrs is
+	Xattrs []string
+	// the values of the extended attributes by name, only set when Options.XattrValues is
+	XattrValues map[string][]byte
+	// the access and default ACL entries, only set when Options.ACLs is
+	ACL []ACLEntry
+	// the extension that the contents of a file look like, e.g. "py" for a script with a python shebang,
+	// or "" if it wasn't sniffed or wasn't recognized. Only set when Options.Sniff is
+	SniffedExt string
+	// 2 characters like `git status --short`, or "" if Options.Git isn't set or the item isn't in a repo
+	GitStatus string
+	// the major and minor numbers of a device file
+	Major uint32
+	Minor uint32
+	// the contents of a folder when listed with Lister.Tree
+	Children *Listing
+}
+
+// Filename is the name of the item.
+func (entry *Entry) Filename() string {
+	return entry.Info.Name()
+}
+
+// IsHidden checks for a dotfile.
+func (entry *Entry) IsHidden() bool {
+	return entry.Basename == "" || entry.Basename[0] == '.'
+}
+
+// IsDir checks whether the item is a folder, or a symlink to one.
+func (entry *Entry) IsDir() bool {
+	return entry.Info.IsDir() || (entry.Link != nil && entry.Link.Info != nil && entry.Link.Info.IsDir())
+}
+
+// HasACL checks the extended attributes for a POSIX access control list.
+func (entry *Entry) HasACL() bool {
+	for _, name := range entry.Xattrs {
+		if name == aclAccessXattr || name == aclDefaultXattr {
+			return true
+		}
+	}
+	return false
+}
+
+// LinkInfo wraps link stat info and whether the link points to valid file.
+type LinkInfo struct {
+	Path   string
+	Info   os.FileInfo
+	Broken bool
+}
+
+// Listing holds the contents of a folder, or of a group of files.
+type Listing struct {
+	// the path as it was passed to the Lister, or "" for the files passed to Lister.ListFiles
+	Path string
+	// the folders (including symlinks to them) and the files, each sorted
+	Dirs  []*Entry
+	Files []*Entry
+	// everything, in the order of Options.Group
+	entries []*Entry
+	// set if the folder couldn't be read at all
+	Err error
+	// problems with single items, which were skipped or are missing some information
+	Errors []error
+	// the real folders to descend into with Lister.Walk, before the --dirs and --files filters
+	subdirs []string
+}
+
+// Entries returns the folders and files together, grouped and sorted by the options of the Lister.
+func (listing *Listing) Entries() []*Entry {
+	return listing.entries
+}
+
+// Lister reads folders with a fixed set of options. It caches what it learns about git repos and ignore
+// files, so reuse the same one for related paths. It's safe to use from several goroutines.
+type Lister struct {
+	opts Options
+	// a token for each worker that is busy, which limits how much is read at the same time
+	workers chan struct{}
+	// the names of the users and groups that own the items
+	ids idNames
+	// guards the git repos and the ignore rules, whose caches are filled in as they're used
+	mu sync.Mutex
+	// every folder we've looked in, mapped to the repo it's in (or nil if it isn't in one)
+	gitRepos map[string]*gitRepo
+	// the ignore rules, and the folder they were set up for
+	ignore     *ignoreMatcher
+	ignoreBase string
+}
+
+// NewLister creates a Lister.
+func NewLister(opts Options) *Lister {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	return &Lister{
+		opts:     opts,
+		workers:  make(chan struct{}, workers),
+		gitRepos: map[string]*gitRepo{},
+	}
+}
+
+// List reads the contents of a folder. If it can't be read, the error is in Listing.Err.
+func (l *Lister) List(pathStr string) *Listing {
+	listing := Listing{Path: pathStr}
+	infos, err := l.readDir(pathStr, &listing)
+	if err != nil {
+		listing.Err = err
+		return &listing
+	}
+	l.collect(&listing, infos, nil, false)
+	for _, info := range infos {
+		if info.IsDir() && (info.Name()[0] != '.' || l.opts.All) {
+			listing.subdirs = append(listing.subdirs, path.Join(pathStr, info.Name()))
+		}
+	}
+	return &listing
+}
+
+// ListFiles builds a listing of files that are already known, e.g. the files passed on the command line,
+// where infos[i] is the stat of paths[i]. Each file is looked up in its own folder, and the listing has no
+// Path. Hidden files are included regardless of Options.All.
+func (l *Lister) ListFiles(paths []string, infos []os.FileInfo) *Listing {
+	listing := Listing{}
+	dirs := make([]string, len(paths))
+	for i, pathStr := range paths {
+		dirs[i] = filepath.Dir(absolutePath(pathStr))
+	}
+	l.collect(&listing, infos, dirs, true)
+	return &listing
+}
+
+// Walk lists a folder and then each folder inside it, depth-first, calling fn with each listing. Hidden
+// folders are only descended into with Options.All, and symlinks are not followed. The subfolders are read
+// ahead by the workers while fn runs, but fn is always called from this goroutine and in the same order.
+func (l *Lister) Walk(pathStr string, fn func(*Listing)) {
+	l.walk(l.listAsync(pathStr), fn)
+}
+
+func (l *Lister) walk(pending *pendingListing, fn func(*Listing)) {
+	listing := pending.wait()
+	children := make([]*pendingListing, len(listing.subdirs))
+	for i, subdir := range listing.subdirs {
+		children[i] = l.listAsync(subdir)
+	}
+	fn(listing)
+	for _, child := range children {
+		l.walk(child, fn)
+	}
+}
+
+// Tree lists a folder and everything inside it, setting the Children of each entry that is a folder. Like
+// Walk, symlinks to folders are not followed, otherwise we could loop forever.
+func (l *Lister) Tree(pathStr string) *Listing {
+	listing := l.List(pathStr)
+	l.forEach(len(listing.Dirs), func(i int) {
+		entry := listing.Dirs[i]
+		if entry.Info.IsDir() {
+			entry.Children = l.Tree(path.Join(pathStr, entry.Info.Name()))
+		}
+	})
+	return listing
+}
+
+// Reads the contents of a folder and filters them by Options.Find and the ignore rules. An error is returned
+// only if the folder can't be read at all. Items that can't be read, e.g. because they were deleted in the
+// meantime, are added to the listing's errors and skipped.
+func (l *Lister) readDir(pathStr string, listing *Listing) ([]os.FileInfo, error) {
+	entries, err := os.ReadDir(pathStr)
+	if err != nil {
+		if len(entries) == 0 {
+			return nil, err
+		}
+		// we got part of the way through, so show what we have
+		listing.Errors = append(listing.Errors, err)
+	}
+
+	// stat the items in parallel, keeping them in order
+	infos := make([]os.FileInfo, len(entries))
+	errs := make([]error, len(entries))
+	l.forEach(len(entries), func(i int) {
+		infos[i], errs[i] = entries[i].Info()
+	})
+	items := make([]os.FileInfo, 0, len(entries))
+	for i, fileInfo := range infos {
+		if errs[i] != nil {
+			listing.Errors = append(listing.Errors, errs[i])
+			continue
+		}
+		items = append(items, fileInfo)
+	}
+
+	// filter by the regexp if one was passed
+	if l.opts.Find != nil {
+		filteredItems := []os.FileInfo{}
+		for _, fileInfo := range items {
+			if l.opts.Find.MatchString(fileInfo.Name()) {
+				filteredItems = append(filteredItems, fileInfo)
+			}
+		}
+		items = filteredItems
+	}
+
+	absPath := absolutePath(pathStr)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if ignore := l.ignoreFor(absPath); ignore != nil {
+		filteredItems := []os.FileInfo{}
+		for _, fileInfo := range items {
+			if !isIgnoredBy(ignore, absPath, fileInfo.Name(), fileInfo.IsDir()) {
+				filteredItems = append(filteredItems, fileInfo)
+			}
+		}
+		items = filteredItems
+	}
+	return items, nil
+}
+
+// Builds the entries for the items and sorts them into the listing. The items are in the folder of the
+// listing, or in the folder at the same index of dirs if it's set. They're looked into in parallel, but any
+// problems are kept in the same order as the items.
+func (l *Lister) collect(listing *Listing, items []os.FileInfo, dirs []string, forceDotfiles bool) {
+	absPath := absolutePath(listing.Path)
+
+	entries := make([]*Entry, len(items))
+	errs := make([][]error, len(items))
+	l.forEach(len(items), func(i int) {
+		dir := absPath
+		if dirs != nil {
+			dir = dirs[i]
+		}
+		entries[i], errs[i] = l.newEntry(items[i], dir, forceDotfiles)
+	})
+
+	for i, entry := range entries {
+		listing.Errors = append(listing.Errors, errs[i]...)
+		if entry == nil {
+			continue
+		}
+		if entry.IsDir() {
+			listing.Dirs = append(listing.Dirs, entry)
+		} else {
+			listing.Files = append(listing.Files, entry)
+		}
+	}
+
+	if l.opts.DirSize {
+		l.sizeDirs(listing)
+	}
+
+	sortByKeys(listing.Dirs, l.opts.Sort)
+	sortByKeys(listing.Files, l.opts.Sort)
+	listing.entries = make([]*Entry, 0, len(listing.Dirs)+len(listing.Files))
+	switch l.opts.Group {
+	case GroupDirsFirst:
+		listing.entries = append(append(listing.entries, listing.Dirs...), listing.Files...)
+	case GroupDirsLast:
+		listing.entries = append(append(listing.entries, listing.Files...), listing.Dirs...)
+	case GroupNone:
+		listing.entries = append(append(listing.entries, listing.Dirs...), listing.Files...)
+		sortByKeys(listing.entries, l.opts.Sort)
+	}
+}
+
+// newEntry finds out everything about an item in a folder, or returns nil if the item isn't listed.
+func (l *Lister) newEntry(fileInfo os.FileInfo, absPath string, forceDotfiles bool) (*Entry, []error) {
+	// if this is a dotfile (hidden file)
+	if fileInfo.Name()[0] == '.' {
+		// we can skip everything with this file if we aren't using the `All` option
+		if !l.opts.All && !forceDotfiles {
+			return nil, nil
+		}
+	}
+
+	basename, ext := splitExt(fileInfo.Name())
+
+	entry := Entry{
+		Info:     fileInfo,
+		Path:     filepath.Join(absPath, fileInfo.Name()),
+		Ext:      ext,
+		Basename: basename,
+		Size:     fileInfo.Size(),
+		DiskSize: diskSize(fileInfo),
+	}
+	entry.OwnDiskSize = entry.DiskSize
+	errs := []error{}
+
+	entry.AccessTime, entry.ChangeTime, entry.BirthTime =
+		fileTimes(fileInfo, path.Join(absPath, fileInfo.Name()), l.opts.TimeField == TimeCreated)
+	switch l.opts.TimeField {
+	case TimeModified:
+		entry.Time = fileInfo.ModTime()
+	case TimeAccessed:
+		entry.Time = entry.AccessTime
+	case TimeChanged:
+		entry.Time = entry.ChangeTime
+	case TimeCreated:
+		entry.Time = entry.BirthTime
+	}
+
+	// read some info about linked file if this item is a symlink
+	if fileInfo.Mode()&os.ModeSymlink != 0 {
+		if err := l.getLinkInfo(&entry, absPath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if entry.IsDir() && l.opts.FilesOnly || !entry.IsDir() && l.opts.DirsOnly {
+		return nil, errs
+	}
+
+	if l.opts.Owners {
+		entry.Owner, entry.Group = getOwnerAndGroup(fileInfo, &l.ids, l.opts.NumericIDs)
+	}
+
+	if l.opts.FileIDs {
+		var err error
+		entry.Inode, entry.Links, err = fileID(fileInfo, path.Join(absPath, fileInfo.Name()))
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if l.opts.Xattrs || l.opts.XattrValues || l.opts.ACLs {
+		fullPath := path.Join(absPath, fileInfo.Name())
+		entry.Xattrs = listXattrs(fullPath)
+		if l.opts.XattrValues {
+			entry.XattrValues = map[string][]byte{}
+			for _, name := range entry.Xattrs {
+				value, err := getXattr(fullPath, name)
+				if err != nil {
+					errs = append(errs, &os.PathError{Op: "getxattr " + name, Path: fullPath, Err: err})
+					continue
+				}
+				entry.XattrValues[name] = value
+			}
+		}
+		if l.opts.ACLs && entry.HasACL() {
+			var err error
+			entry.ACL, err = l.readACL(&entry, fullPath)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if l.opts.Sniff && fileInfo.Mode().IsRegular() && fileInfo.Size() > 0 && !hasKnownType(basename, ext) {
+		var err error
+		entry.SniffedExt, err = sniffExt(path.Join(absPath, fileInfo.Name()))
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if fileInfo.Mode()&os.ModeDevice != 0 {
+		var err error
+		entry.Major, entry.Minor, err = deviceNumbers(path.Join(absPath, fileInfo.Name()))
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if l.opts.Git {
+		l.mu.Lock()
+		entry.GitStatus = l.gitStatusForItem(absPath, &entry)
+		l.mu.Unlock()
+	}
+	return &entry, errs
+}
+
+// getLinkInfo reads where a symlink points. An error is returned if the link itself can't be read.
+func (l *Lister) getLinkInfo(entry *Entry, absPath string) error {
+	fullPath := path.Join(absPath, entry.Info.Name())
+	linkPath, err1 := os.Readlink(fullPath)
+	if err1 != nil {
+		// we can't tell where it goes, so it's as good as broken
+		entry.Link = &LinkInfo{Broken: true}
+		return err1
+	}
+
+	linkFullPath := linkPath
+	if linkPath[0] != '/' {
+		linkFullPath = path.Join(absPath, linkPath)
+	}
+
+	linkInfo, err2 := os.Stat(linkFullPath)
+	if l.opts.LinkRel {
+		linkRel, _ := filepath.Rel(absPath, linkPath)
+		if linkRel != "" && len(linkRel) <= len(linkPath) {
+			// i prefer the look of these relative paths prepended with ./
+			if linkRel[0] != '.' {
+				linkPath = "./" + linkRel
+			} else {
+				linkPath = linkRel
+			}
+		}
+	}
+
+	link := LinkInfo{
+		Path: linkPath,
+	}
+	entry.Link = &link
+	if linkInfo != nil {
+		link.Info = linkInfo
+	} else if !os.IsPermission(err2) {
+		// this also covers other ways a link can be unresolvable, like a loop of links
+		link.Broken = true
+	}
+	return nil
+}
+
+func splitExt(filename string) (basepath, ext string) {
+	basename := filepath.Base(filename)
+	ext = filepath.Ext(filename)
+	basepath = strings.TrimSuffix(basename, ext)
+	if len(ext) > 0 {
+		ext = ext[1:]
+	}
+	return
+}
+
+// absolutePath falls back to the path as given if the working directory can't be determined.
+func absolutePath(pathStr string) string {
+	absPath, err := filepath.Abs(pathStr)
+	if err != nil {
+		return pathStr
+	}
+	return absPath
+}
@@ -0,0 +1,143 @@
+package lsgo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestListFilesFromSeveralFolders(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a/one.txt", "b/two.txt"} {
+		fullPath := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte("hello"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	chdir(t, filepath.Join(dir, "a"))
+
+	paths := []string{"one.txt", "../b/two.txt"}
+	infos := make([]os.FileInfo, len(paths))
+	for i, pathStr := range paths {
+		var err error
+		if infos[i], err = os.Stat(pathStr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	listing := NewLister(Options{Sniff: true, FileIDs: true}).ListFiles(paths, infos)
+	if len(listing.Errors) > 0 {
+		t.Fatal(listing.Errors)
+	}
+	if listing.Path != "" {
+		t.Errorf("expected no path for the file arguments, got %q", listing.Path)
+	}
+	expected := map[string]string{
+		"one": filepath.Join(dir, "a", "one.txt"),
+		"two": filepath.Join(dir, "b", "two.txt"),
+	}
+	for _, entry := range listing.Files {
+		if entry.Path != expected[entry.Basename] {
+			t.Errorf("expected %s to be at %s, got %s", entry.Basename, expected[entry.Basename], entry.Path)
+		}
+	}
+}
+
+// chdir changes the working directory until the end of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// writeTestFile creates a file and the folders it's in.
+func writeTestFile(t *testing.T, filePath string, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filePath, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWalkOrder(t *testing.T) {
+	dir := t.TempDir()
+	expected := []string{dir}
+	for _, top := range []string{"a", "b", "c"} {
+		expected = append(expected, filepath.Join(dir, top))
+		for _, sub := range []string{"1", "2", "3", "4"} {
+			subdir := filepath.Join(dir, top, sub)
+			writeTestFile(t, filepath.Join(subdir, "deep", "file"), "")
+			expected = append(expected, subdir, filepath.Join(subdir, "deep"))
+		}
+	}
+	writeTestFile(t, filepath.Join(dir, ".hidden", "file"), "")
+	if err := os.Symlink("a", filepath.Join(dir, "link")); err != nil {
+		t.Skip("can't make symlinks:", err)
+	}
+
+	// however many folders are read ahead, they come out depth-first, sorted by name, and without the
+	// hidden folders or the symlinks
+	for _, workers := range []int{1, 2, 4, 64} {
+		for i := 0; i < 5; i++ {
+			visited := []string{}
+			NewLister(Options{Workers: workers}).Walk(dir, func(listing *Listing) {
+				visited = append(visited, listing.Path)
+			})
+			if !reflect.DeepEqual(visited, expected) {
+				t.Fatalf("%d workers: visited %q, want %q", workers, visited, expected)
+			}
+		}
+	}
+}
+
+func TestGrouping(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a-dir/file", "z-dir/file", "b", "y"} {
+		writeTestFile(t, filepath.Join(dir, name), "")
+	}
+	if err := os.Symlink("z-dir", filepath.Join(dir, "m-link")); err != nil {
+		t.Skip("can't make symlinks:", err)
+	}
+	tests := []struct {
+		group   Grouping
+		reverse bool
+		names   []string
+	}{
+		// a symlink to a folder goes with the folders
+		{GroupDirsFirst, false, []string{"a-dir", "m-link", "z-dir", "b", "y"}},
+		{GroupDirsLast, false, []string{"b", "y", "a-dir", "m-link", "z-dir"}},
+		{GroupNone, false, []string{"a-dir", "b", "m-link", "y", "z-dir"}},
+		// reversing the order doesn't move the groups
+		{GroupDirsFirst, true, []string{"z-dir", "m-link", "a-dir", "y", "b"}},
+		{GroupNone, true, []string{"z-dir", "y", "m-link", "b", "a-dir"}},
+	}
+	for _, test := range tests {
+		opts := Options{Group: test.group, Sort: []SortKey{{SortByName, test.reverse}}}
+		listing := listTestDir(t, opts, dir)
+		names := []string{}
+		for _, entry := range listing.Entries() {
+			names = append(names, entry.Filename())
+		}
+		if !reflect.DeepEqual(names, test.names) {
+			t.Errorf("grouping %d, reversed %v: %q, want %q", test.group, test.reverse, names, test.names)
+		}
+		// the renderer keeps the same order
+		rendered := strings.Fields(renderTestListing(t, RenderOptions{}, listing))
+		if !reflect.DeepEqual(rendered, test.names) {
+			t.Errorf("grouping %d, reversed %v: rendered %q, want %q", test.group, test.reverse, rendered,
+				test.names)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+//go:build !windows
+
+package lsgo
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// getOwnerAndGroup returns the names of the owner and group of a file, or their ids if the names are
+// unknown or numeric is set.
+func getOwnerAndGroup(fileInfo os.FileInfo, ids *idNames, numeric bool) (string, string) {
+	statT := fileInfo.Sys().(*syscall.Stat_t)
+	uid := fmt.Sprint(statT.Uid)
+	gid := fmt.Sprint(statT.Gid)
+	if numeric {
+		return uid, gid
+	}
+
+	ownerName := ids.lookup("user", uid, func() string {
+		owner, err := user.LookupId(uid)
+		if err != nil {
+			return uid
+		}
+		return owner.Username
+	})
+	groupName := ids.lookup("group", gid, func() string {
+		group, err := user.LookupGroupId(gid)
+		if err != nil {
+			return gid
+		}
+		return group.Name
+	})
+	return ownerName, groupName
+}
+
+func deviceNumbers(absPath string) (uint32, uint32, error) {
+	stat := syscall.Stat_t{}
+	err := syscall.Stat(absPath, &stat)
+	if err != nil {
+		return 0, 0, &os.PathError{Op: "stat", Path: absPath, Err: err}
+	}
+	return unix.Major(uint64(stat.Rdev)), unix.Minor(uint64(stat.Rdev)), nil
+}
+
+// fileID returns the inode number and the number of hard links, which are already in the stat info.
+func fileID(info os.FileInfo, absPath string) (uint64, uint64, error) {
+	statT := info.Sys().(*syscall.Stat_t)
+	return uint64(statT.Ino), uint64(statT.Nlink), nil
+}
+
+// diskSize is the space a file takes up on disk, which is counted in 512-byte blocks on every unix.
+func diskSize(info os.FileInfo) int64 {
+	return info.Sys().(*syscall.Stat_t).Blocks * 512
+}
+
+// Identifies a file by its device and inode numbers.
+type fileKey struct {
+	dev uint64
+	ino uint64
+}
+
+// hardLinkKey returns the identity of a file that has more than one hard link, so it can be counted once.
+func hardLinkKey(info os.FileInfo) (fileKey, bool) {
+	statT := info.Sys().(*syscall.Stat_t)
+	if statT.Nlink <= 1 {
+		return fileKey{}, false
+	}
+	return fileKey{uint64(statT.Dev), uint64(statT.Ino)}, true
+}
+
+// termWidth asks the terminal how many columns wide it is.
+func termWidth(file *os.File) int {
+	size, err := unix.IoctlGetWinsize(int(file.Fd()), unix.TIOCGWINSZ)
+	if err != nil || size.Col == 0 {
+		return fallbackTermWidth()
+	}
+	return int(size.Col)
+}
@@ -0,0 +1,70 @@
+package lsgo
+
+import (
+	"strings"
+)
+
+// lsColorKeys maps the file type keys of LS_COLORS to the entries of ConfigColor they replace.
+var lsColorKeys = map[string][][2]string{
+	"di": {{"dir", "name"}, {".dir", "name"}},
+	"ln": {{"link", "name"}, {"link", "nameDir"}, {"link", "arrow"}, {"link", "arrowDir"}},
+	"or": {{"link", "broken"}},
+	"pi": {{"pipe", "name"}},
+	"so": {{"socket", "name"}},
+	"bd": {{"device", "name"}},
+	"cd": {{"device", "name"}},
+}
+
+// ApplyLSColors overrides the built-in colors with the ones in the LS_COLORS environment variable, which is
+// in the format generated by `dircolors`, e.g. "di=01;34:ln=01;36:*.tar=01;31". It must be called after
+// GenerateColors.
+func ApplyLSColors(lsColors string) {
+	if !colorEnabled {
+		return
+	}
+	for _, entry := range strings.Split(lsColors, ":") {
+		eq := strings.Index(entry, "=")
+		if eq < 0 {
+			continue
+		}
+		key, value := entry[:eq], entry[eq+1:]
+		if value == "" || !isSGR(value) {
+			continue
+		}
+		color := "\x1b[" + value + "m"
+
+		switch {
+		case strings.HasPrefix(key, "*."):
+			ext := strings.ToLower(key[2:])
+			// we only color by the last extension, so patterns like "*.tar.gz" can't be honored
+			if ext != "" && !strings.Contains(ext, ".") {
+				setFileColor(ext, FileColorConfig{color, color, false})
+			}
+		case key == "fi":
+			FileColor["_default"] = FileColorConfig{color, color, false}
+		case key == "ex":
+			FileColor["_executable"] = FileColorConfig{color, color, false}
+		default:
+			for _, configKey := range lsColorKeys[key] {
+				ConfigColor[configKey[0]][configKey[1]] = color
+			}
+		}
+	}
+}
+
+// isSGR checks that an LS_COLORS value is a list of ANSI "Select Graphic Rendition" parameters, like "01;34"
+func isSGR(value string) bool {
+	for _, char := range value {
+		if (char < '0' || char > '9') && char != ';' {
+			return false
+		}
+	}
+	return true
+}
+
+// setFileColor sets the color for a file extension. The extension is no longer treated as an alias, so the
+// new color is used instead of the color of the canonical extension.
+func setFileColor(ext string, colors FileColorConfig) {
+	delete(FileAliases, ext)
+	FileColor[ext] = colors
+}
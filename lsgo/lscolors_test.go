@@ -0,0 +1,77 @@
+package lsgo
+
+import (
+	"testing"
+)
+
+func TestApplyLSColors(t *testing.T) {
+	GenerateColors(false)
+	defer GenerateColors(false)
+	keepFileAliases(t)
+	defaultPipe := ConfigColor["pipe"]["name"]
+	defaultTgz := FileColor["tgz"]
+
+	ApplyLSColors("rs=0:di=01;34:ln=01;36:or=40;31;01:ex=01;32:fi=00:*.tar=01;31:*.TXT=35:*.tar.gz=33:" +
+		"*.tgz=bogus:pi=:so:*.go=38;5;208")
+
+	tests := []struct {
+		name  string
+		color string
+		want  string
+	}{
+		{"dir", ConfigColor["dir"]["name"], "\x1b[01;34m"},
+		{"hidden dir", ConfigColor[".dir"]["name"], "\x1b[01;34m"},
+		{"link", ConfigColor["link"]["name"], "\x1b[01;36m"},
+		{"link arrow", ConfigColor["link"]["arrowDir"], "\x1b[01;36m"},
+		{"broken link", ConfigColor["link"]["broken"], "\x1b[40;31;01m"},
+		{"executable", FileColor["_executable"].light, "\x1b[01;32m"},
+		{"default", FileColor["_default"].dark, "\x1b[00m"},
+		{"tar", FileColor["tar"].light, "\x1b[01;31m"},
+		{"upper case extension", FileColor["txt"].light, "\x1b[35m"},
+		{"256 colors", FileColor["go"].dark, "\x1b[38;5;208m"},
+		{"empty value", ConfigColor["pipe"]["name"], defaultPipe},
+		{"invalid value", FileColor["tgz"].light, defaultTgz.light},
+	}
+	for _, test := range tests {
+		if test.color != test.want {
+			t.Errorf("%s color = %q, want %q", test.name, test.color, test.want)
+		}
+	}
+	if _, hasColor := FileColor["tar.gz"]; hasColor {
+		t.Errorf("the color of *.tar.gz was set for the extension \"tar.gz\"")
+	}
+}
+
+func TestApplyLSColorsReplacesAliases(t *testing.T) {
+	GenerateColors(false)
+	defer GenerateColors(false)
+	keepFileAliases(t)
+	FileAliases["h"] = "c"
+	ApplyLSColors("*.h=01;35")
+	if _, isAlias := FileAliases["h"]; isAlias {
+		t.Errorf("*.h is still an alias after setting its color")
+	}
+	if color := FileColor["h"].light; color != "\x1b[01;35m" {
+		t.Errorf("FileColor[h] = %q, want %q", color, "\x1b[01;35m")
+	}
+}
+
+func TestIsSGR(t *testing.T) {
+	tests := []struct {
+		value string
+		isSGR bool
+	}{
+		{"0", true},
+		{"01;34", true},
+		{"38;5;208", true},
+		{"", true},
+		{"01;3x", false},
+		{"\x1b[0", false},
+		{" 01", false},
+	}
+	for _, test := range tests {
+		if isSGR := isSGR(test.value); isSGR != test.isSGR {
+			t.Errorf("isSGR(%q) = %v, want %v", test.value, isSGR, test.isSGR)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package lsgo
+
+import (
+	"sync"
+)
+
+// idNames remembers the names of users and groups, since each lookup can mean asking a directory service
+// like LDAP. It's shared by every folder a Lister reads.
+type idNames struct {
+	mu    sync.Mutex
+	names map[string]string
+}
+
+// lookup returns the cached name for an id of some kind (e.g. "user" or "group"), calling find to look it
+// up the first time.
+func (ids *idNames) lookup(kind string, id string, find func() string) string {
+	key := kind + ":" + id
+	ids.mu.Lock()
+	name, isCached := ids.names[key]
+	ids.mu.Unlock()
+	if isCached {
+		return name
+	}
+
+	name = find()
+	ids.mu.Lock()
+	if ids.names == nil {
+		ids.names = map[string]string{}
+	}
+	ids.names[key] = name
+	ids.mu.Unlock()
+	return name
+}
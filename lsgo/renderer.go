@@ -0,0 +1,605 @@
+package lsgo
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RenderOptions controls which columns a Renderer shows for each entry and how names are decorated.
+type RenderOptions struct {
+	// the columns of the long format, which are shown in the order of Columns if it's set
+	Inode     bool
+	Blocks    bool
+	Perms     bool
+	LinkCount bool
+	Owner     bool
+	NoGroup   bool
+	Bytes     bool
+	Mdate     bool
+	Git       bool
+	// the columns to show, in order, in place of the ones turned on above
+	Columns []Column
+	// print the titles of the columns above the long format
+	Header bool
+	// show where symlinks point
+	Links bool
+	// show the extended attributes, with their sizes or values if they were read, and the ACL entries
+	// beneath each name
+	Xattrs      bool
+	XattrValues bool
+	ACL         bool
+	// show emoji icons, or nerd font glyphs, before the names
+	Icons    bool
+	NerdFont bool
+	// print each listing as a line of JSON instead of colored text
+	JSON bool
+	// arrange short listings in columns like `ls`, which only makes sense in a terminal
+	Grid bool
+	// how many characters wide the grid can be, or 0 to ask the terminal being written to, or os.Stdout if
+	// the Renderer isn't writing to a file
+	Width int
+	// make the names links to the files with OSC 8 escape sequences, which many terminals can open
+	Hyperlink bool
+	// where the folders that couldn't be read are reported, or os.Stderr if nil
+	ErrOut io.Writer
+	// how timestamps are written: "" for the default, "relative", "iso", "long-iso", "full", or a Go time
+	// layout after a "+", e.g. "+2006-01-02 15:04:05"
+	TimeStyle string
+	// how timestamps are colored
+	TimeColor TimeColor
+	// how sizes are written
+	SizeStyle SizeStyle
+	// the size of a block for SizeBlocks and the blocks column, 1024 if not set
+	BlockSize int64
+}
+
+// Renderer prints listings in the style of ls-go. The colors come from the package's color maps, so
+// GenerateColors must be called first.
+type Renderer struct {
+	out   io.Writer
+	opts  RenderOptions
+	start time.Time
+	// the host name in the file:// URLs of hyperlinks
+	host string
+	// the first error from writing JSON
+	err error
+}
+
+// Wraps an entry and the strings to be printed.
+type displayItem struct {
+	*Entry
+	// the long format columns
+	details string
+	// the name, and where it links to
+	label string
+	// the columns after the name, if it isn't the last one
+	after string
+	// the extended attributes and ACL entries, which are shown beneath the name
+	attrs []string
+}
+
+// NewRenderer creates a Renderer. The time it was created is the start time for Stats.
+func NewRenderer(out io.Writer, opts RenderOptions) *Renderer {
+	r := &Renderer{
+		out:   out,
+		opts:  opts,
+		start: time.Now(),
+	}
+	if opts.Hyperlink {
+		// terminals only open links to files on their own host, and the name is how they check
+		r.host, _ = os.Hostname()
+	}
+	return r
+}
+
+// Err returns the first error that happened while writing JSON.
+func (r *Renderer) Err() error {
+	return r.err
+}
+
+func (r *Renderer) isLongFormat() bool {
+	columns := r.columns()
+	return len(columns) != 1 || columns[0] != ColumnName || r.opts.Xattrs || r.opts.ACL
+}
+
+// Listing prints the entries of a listing, with the folders where Options.Group put them. Nothing is printed
+// for an empty listing, except in JSON mode.
+func (r *Renderer) Listing(listing *Listing) {
+	items, header := r.collectItems(listing)
+
+	if r.opts.JSON {
+		r.printJSONListing(listing.Path, items)
+		return
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	// if using "long" display, or if the output is going to another program, just print one item per line
+	if r.isLongFormat() || !r.opts.Grid {
+		if header != "" {
+			fmt.Fprintln(r.out, header)
+		}
+		for _, item := range items {
+			fmt.Fprintln(r.out, item.details+item.label+item.after)
+			for _, attr := range item.attrs {
+				// nested a little beneath the name
+				fmt.Fprintln(r.out, attrIndent(item)+"   "+attr)
+			}
+		}
+	} else {
+		// but if not, try to format in columns, link `ls` would
+		strs := []string{}
+		for _, item := range items {
+			strs = append(strs, item.details+item.label)
+		}
+		r.printGrid(strs, 2)
+	}
+}
+
+// Builds the display strings for each entry, folders first, and the header above them.
+func (r *Renderer) collectItems(listing *Listing) ([]*displayItem, string) {
+	entries := listing.Entries()
+	items := make([]*displayItem, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, &displayItem{Entry: entry})
+	}
+	// the JSON output is built from the raw stat info, so skip building the display strings
+	if r.opts.JSON {
+		return items, ""
+	}
+
+	header := r.layoutTable(items)
+	if r.opts.Xattrs || r.opts.ACL {
+		for _, item := range items {
+			item.attrs = r.attrLines(item.Entry)
+		}
+	}
+	return items, header
+}
+
+// Name renders the name of an entry with its colors and icon, like in a short listing.
+func (r *Renderer) Name(entry *Entry) string {
+	return r.nameString(entry)
+}
+
+// nameString renders the name of an entry, which links to the file with RenderOptions.Hyperlink.
+func (r *Renderer) nameString(item *Entry) string {
+	return r.hyperlink(item.Path, r.coloredName(item))
+}
+
+func (r *Renderer) coloredName(item *Entry) string {
+	mode := item.Info.Mode()
+	name := item.Info.Name()
+	if mode&os.ModeDir != 0 {
+		return r.dirString(item)
+	} else if mode&os.ModeSymlink != 0 {
+		if item.IsDir() {
+			color := ConfigColor["link"]["nameDir"]
+			if r.opts.NerdFont {
+				var linkIcon string
+				if item.Link.Broken {
+					linkIcon = otherIcons["brokenLink"]
+				} else {
+					linkIcon = otherIcons["linkDir"]
+				}
+				return color + linkIcon + " " + name + " " + Reset
+			} else if r.opts.Icons {
+				return color + "🔗 " + name + " " + Reset
+			} else {
+				return color + " " + name + " " + Reset
+			}
+		} else {
+			color := ConfigColor["link"]["name"]
+			if r.opts.NerdFont {
+				var linkIcon string
+				if item.Link.Broken {
+					linkIcon = otherIcons["brokenLink"]
+				} else {
+					linkIcon = otherIcons["link"]
+				}
+				return color + linkIcon + " " + name + " " + Reset
+			} else if r.opts.Icons {
+				return color + "🔗 " + name + " " + Reset
+			} else {
+				return color + " " + name + " " + Reset
+			}
+		}
+	} else if mode&os.ModeDevice != 0 {
+		color := ConfigColor["device"]["name"]
+		if r.opts.NerdFont {
+			return color + otherIcons["device"] + " " + name + " " + Reset
+		} else if r.opts.Icons {
+			return color + "💽 " + name + " " + Reset
+		} else {
+			return color + " " + name + " " + Reset
+		}
+	} else if mode&os.ModeNamedPipe != 0 {
+		color := ConfigColor["pipe"]["name"]
+		if r.opts.NerdFont {
+			return color + otherIcons["pipe"] + " " + name + " " + Reset
+		} else if r.opts.Icons {
+			return color + "🛢 " + name + " " + Reset
+		} else {
+			return color + " " + name + " " + Reset
+		}
+	} else if mode&os.ModeSocket != 0 {
+		color := ConfigColor["socket"]["name"]
+		if r.opts.NerdFont {
+			return color + otherIcons["socket"] + " " + name + " " + Reset
+		} else if r.opts.Icons {
+			return color + "🔌 " + name + " " + Reset
+		} else {
+			return color + " " + name + " " + Reset
+		}
+	}
+	return r.fileString(item)
+}
+
+func (r *Renderer) linkString(item *Entry) string {
+	colors := ConfigColor["link"]
+	displayStrings := []string{}
+	if item.Link.Info == nil && item.Link.Broken {
+		displayStrings = append(displayStrings, colors["broken"]+"►", item.Link.Path+Reset)
+	} else if item.Link.Info != nil {
+		linkname, linkext := splitExt(item.Link.Path)
+		target := Entry{
+			Info:     item.Link.Info,
+			Basename: linkname,
+			Ext:      linkext,
+			Path:     linkTarget(item),
+		}
+		arrowColor := colors["arrow"]
+		if target.Info.IsDir() {
+			arrowColor = colors["arrowDir"]
+		}
+		displayStrings = append(displayStrings, arrowColor+"►", r.nameString(&target))
+	} else {
+		displayStrings = append(displayStrings, r.hyperlink(linkTarget(item), item.Link.Path))
+	}
+	return strings.Join(displayStrings, " ")
+}
+
+// linkTarget is the absolute path that a symlink points to, since the path shown can be relative.
+func linkTarget(item *Entry) string {
+	if item.Path == "" || filepath.IsAbs(item.Link.Path) {
+		return item.Link.Path
+	}
+	return filepath.Join(filepath.Dir(item.Path), item.Link.Path)
+}
+
+// fileIcon picks the nerd font glyph for a file by its name, or by its contents if they were sniffed.
+func (r *Renderer) fileIcon(item *Entry) string {
+	if item.SniffedExt != "" {
+		return GetIconForFile("", item.SniffedExt)
+	}
+	return GetIconForFile(item.Basename, item.Ext)
+}
+
+func (r *Renderer) fileString(item *Entry) string {
+	key := strings.ToLower(item.Ext)
+	if item.SniffedExt != "" {
+		key = item.SniffedExt
+	}
+	// figure out which color to choose
+	colors := FileColor["_default"]
+	alias, hasAlias := FileAliases[key]
+	if hasAlias {
+		key = alias
+	}
+	betterColor, hasBetterColor := FileColor[key]
+	if hasBetterColor {
+		colors = betterColor
+	}
+	// like `ls`, the color for executables takes precedence over the extension, but only if one is configured
+	execColor, hasExecColor := FileColor["_executable"]
+	if hasExecColor && isExecutableScript(item) {
+		colors = execColor
+	}
+
+	ext := item.Ext
+	if ext != "" {
+		ext = "." + ext
+	}
+
+	mainColor := colors.light
+	accentColor := colors.dark
+	if lightTheme && colors.themeSwitch {
+		mainColor = colors.dark
+		accentColor = colors.light
+	}
+	// in some cases files have icons if front
+	// if nerd font enabled, then it'll be a file-specific icon, or if its an executable script, a little shell icon
+	// if the regular --icons flag is used instead, then it will show a ">_" only if the file is executable
+	icon := ""
+	executable := isExecutableScript(item)
+	if r.opts.NerdFont {
+		if executable {
+			icon = mainColor + GetIconForFile("", "shell") + " "
+		} else {
+			icon = mainColor + r.fileIcon(item) + " "
+		}
+	} else if r.opts.Icons {
+		if executable {
+			icon = BgGray(1) + NamedFg(BrightGreen) + ">_" + Reset + " "
+		}
+	} else {
+		icon = " "
+	}
+
+	displayStrings := []string{icon}
+
+	if item.IsHidden() {
+		displayStrings = append(displayStrings, accentColor, item.Basename, ext, Reset)
+	} else {
+		displayStrings = append(displayStrings, mainColor, item.Basename, accentColor, ext, Reset)
+	}
+	return strings.Join(displayStrings, "")
+}
+
+// check for executable permissions
+func isExecutableScript(item *Entry) bool {
+	if item.Info.Mode()&0111 != 0 && item.Info.Mode().IsRegular() {
+		return true
+	}
+	return false
+}
+
+func (r *Renderer) dirString(item *Entry) string {
+	colors := ConfigColor["dir"]
+	if item.Basename == "" {
+		colors = ConfigColor[".dir"]
+	}
+	displayStrings := []string{colors["name"]}
+	icon := ""
+	if r.opts.Icons {
+		displayStrings = append(displayStrings, "📂 ")
+	} else if r.opts.NerdFont {
+		icon = GetIconForFolder(item.Info.Name()) + " "
+		displayStrings = append(displayStrings, icon)
+	} else {
+		displayStrings = append(displayStrings, " ")
+	}
+	ext := item.Ext
+	if ext != "" {
+		ext = "." + ext
+	}
+	displayStrings = append(displayStrings, item.Basename, colors["ext"], ext, " ", Reset)
+	return strings.Join(displayStrings, "")
+}
+
+// rwxString builds one of the 3 triads of the permissions string, where i is 2 for the owner, 1 for the group,
+// and 0 for others. Like `ls`, the execute position also shows the setuid, setgid, and sticky bits, in lower
+// case if the execute bit is set too and upper case if it isn't.
+func rwxString(mode os.FileMode, i uint, color string) string {
+	bits := mode >> (i * 3)
+	special := PermsColor["special"]
+	coloredStrings := []string{color}
+	if bits&4 != 0 {
+		coloredStrings = append(coloredStrings, "r")
+	} else {
+		coloredStrings = append(coloredStrings, "-")
+	}
+	if bits&2 != 0 {
+		// anyone can write to this, which is only safe in folders like /tmp that have the sticky bit
+		if i == 0 && mode&os.ModeSymlink == 0 && mode&os.ModeSticky == 0 {
+			coloredStrings = append(coloredStrings, special["worldWritable"]+"w"+Reset+color)
+		} else {
+			coloredStrings = append(coloredStrings, "w")
+		}
+	} else {
+		coloredStrings = append(coloredStrings, "-")
+	}
+
+	specialBit, specialChar, specialColor := os.FileMode(0), "", ""
+	switch i {
+	case 2:
+		specialBit, specialChar, specialColor = os.ModeSetuid, "s", special["setuid"]
+	case 1:
+		specialBit, specialChar, specialColor = os.ModeSetgid, "s", special["setgid"]
+	case 0:
+		specialBit, specialChar, specialColor = os.ModeSticky, "t", special["sticky"]
+	}
+	if mode&specialBit != 0 {
+		if bits&1 == 0 {
+			specialChar = strings.ToUpper(specialChar)
+		}
+		coloredStrings = append(coloredStrings, specialColor+specialChar+Reset)
+	} else if bits&1 != 0 {
+		coloredStrings = append(coloredStrings, "x")
+	} else {
+		coloredStrings = append(coloredStrings, "-")
+	}
+	return strings.Join(coloredStrings, "")
+}
+
+// generates the permissions string, ya know like "drwxr-xr-x" and stuff like that
+func permString(item *Entry, ownerColor string, groupColor string) string {
+	defaultColor := PermsColor["other"]["_default"]
+
+	// info.Mode().String() does not produce the same output as `ls`, so we must build that string manually
+	mode := item.Info.Mode()
+	coloredStrings := []string{defaultColor, fileTypeChar(mode), " "}
+	coloredStrings = append(coloredStrings, rwxString(mode, 2, ownerColor))
+	coloredStrings = append(coloredStrings, rwxString(mode, 1, groupColor))
+	coloredStrings = append(coloredStrings, rwxString(mode, 0, defaultColor))
+	coloredStrings = append(coloredStrings, attrIndicator(item), Reset)
+	return strings.Join(coloredStrings, "")
+}
+
+// fileTypeChar is the first letter of the permissions in `ls -l`. This "type" is not the file extension, but
+// type as far as the OS is concerned.
+func fileTypeChar(mode os.FileMode) string {
+	if mode&os.ModeDir != 0 {
+		return "d"
+	} else if mode&os.ModeSymlink != 0 {
+		return "l"
+	} else if mode&os.ModeDevice != 0 {
+		if mode&os.ModeCharDevice == 0 {
+			return "b" // block device
+		}
+		return "c" // character device
+	} else if mode&os.ModeNamedPipe != 0 {
+		return "p"
+	} else if mode&os.ModeSocket != 0 {
+		return "s"
+	} else if mode&os.ModeIrregular != 0 {
+		return "?"
+	}
+	return "-"
+}
+
+// attrIndicator follows the permissions like in `ls -l`: "+" for an access control list, "." for only an
+// SELinux context, and "@" for any other extended attributes.
+func attrIndicator(item *Entry) string {
+	special := PermsColor["special"]
+	if item.HasACL() {
+		return special["acl"] + "+"
+	}
+	hasContext := false
+	for _, name := range item.Xattrs {
+		if name == "security.selinux" {
+			hasContext = true
+		} else {
+			return special["xattr"] + "@"
+		}
+	}
+	if hasContext {
+		return special["xattr"] + "."
+	}
+	return " "
+}
+
+// device files show their major and minor numbers in place of the size, like `ls`
+func deviceString(major, minor uint32) string {
+	return strconv.FormatUint(uint64(major), 10) + "," + strconv.FormatUint(uint64(minor), 10)
+}
+
+// FolderHeader prints a folder's path conspicuously above its contents. This helps with visual separation.
+// In JSON mode the path is a field of the listing instead, so nothing is printed.
+func (r *Renderer) FolderHeader(pathStr string) {
+	if r.opts.JSON {
+		return
+	}
+	colors := ConfigColor["folderHeader"]
+	headerString := colors["arrow"] + "►" + colors["main"] + " "
+	prettyPath := PrettifyPath(pathStr)
+
+	if prettyPath == "/" {
+		headerString += "/"
+	} else {
+		folders := strings.Split(prettyPath, "/")
+		coloredFolders := make([]string, 0, len(folders))
+		for i, folder := range folders {
+			// Use different color for the last folder in the path.
+			if i == len(folders)-1 {
+				coloredFolders = append(coloredFolders, colors["lastFolder"]+folder)
+			} else {
+				coloredFolders = append(coloredFolders, colors["main"]+folder)
+			}
+		}
+		headerString += strings.Join(coloredFolders, colors["slash"]+"/")
+	}
+
+	fmt.Fprintln(r.out, headerString+" "+Reset)
+}
+
+// ErrorHeader reports an error in place of the contents of a folder, using error-looking colors. It goes to
+// RenderOptions.ErrOut, except in JSON mode, where it's a listing with an error on the usual output.
+func (r *Renderer) ErrorHeader(err error, pathStr string) {
+	if r.opts.JSON {
+		r.printJSONError(err, pathStr)
+		return
+	}
+	errOut := r.opts.ErrOut
+	if errOut == nil {
+		errOut = os.Stderr
+	}
+	fmt.Fprintln(errOut, ConfigColor["folderHeader"]["error"]+"► "+pathStr+Reset)
+	fmt.Fprintln(errOut, err.Error())
+}
+
+// Separator prints a blank line between folders, except in JSON mode.
+func (r *Renderer) Separator() {
+	if !r.opts.JSON {
+		fmt.Fprintln(r.out, "")
+	}
+}
+
+// PrettifyPath shortens a path relative to the working directory or the home folder.
+func PrettifyPath(pathStr string) string {
+	prettyPath := absolutePath(pathStr)
+	pwd := os.Getenv("PWD")
+	home := os.Getenv("HOME")
+
+	if strings.HasPrefix(prettyPath, pwd) {
+		prettyPath = "." + prettyPath[len(pwd):]
+	} else if strings.HasPrefix(prettyPath, home) {
+		prettyPath = "~" + prettyPath[len(home):]
+	}
+	return prettyPath
+}
+
+func getOwnerAndGroupColors(owner string, group string) (string, string) {
+	if owner == os.Getenv("USER") {
+		owner = "_self"
+	}
+	ownerColor := PermsColor["user"][owner]
+	if ownerColor == "" {
+		ownerColor = PermsColor["user"]["_default"]
+	}
+	groupColor := PermsColor["group"][group]
+	if groupColor == "" {
+		groupColor = PermsColor["group"]["_default"]
+	}
+	return ownerColor, groupColor
+}
+
+// Total prints the number of blocks taken up by the entries of a folder, like the first line of `ls -s`.
+// Folders only count their own blocks, even with Options.DirSize, like in `ls`. It's only printed when the
+// blocks are shown.
+func (r *Renderer) Total(listing *Listing) {
+	if r.opts.JSON || !r.hasColumn(ColumnBlocks) {
+		return
+	}
+	var diskSize int64
+	for _, entry := range listing.Entries() {
+		diskSize += entry.OwnDiskSize
+	}
+	colors := ConfigColor["counts"]
+	fmt.Fprintln(r.out, colors["total"]+"total "+strconv.FormatInt(r.blockCount(diskSize), 10)+Reset)
+}
+
+// Stats prints the number of folders and files, and the time since the Renderer was created.
+func (r *Renderer) Stats(numFiles, numDirs int) {
+	if r.opts.JSON {
+		return
+	}
+	colors := ConfigColor["stats"]
+	microSeconds := time.Since(r.start) / time.Microsecond
+	milliSeconds := float64(microSeconds) / 1000
+	statStrings := []string{
+		colors["text"],
+		colors["number"] + strconv.Itoa(numDirs),
+		colors["text"] + "dirs",
+		colors["number"] + strconv.Itoa(numFiles),
+		colors["text"] + "files",
+		colors["ms"] + fmt.Sprintf("%.2f", milliSeconds),
+		colors["text"] + "ms",
+		Reset,
+	}
+	fmt.Fprintln(r.out, strings.Join(statStrings, " "))
+}
+
+// Go doesn't provide a `Max` function for ints like it does for floats (wtf?)
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
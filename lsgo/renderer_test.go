@@ -0,0 +1,140 @@
+package lsgo
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// listTestDir lists a folder with a fresh Lister and fails the test if it couldn't be read.
+func listTestDir(t *testing.T, opts Options, dir string) *Listing {
+	t.Helper()
+	listing := NewLister(opts).List(dir)
+	if listing.Err != nil {
+		t.Fatal(listing.Err)
+	}
+	return listing
+}
+
+// disableColor turns off the colors until the end of the test.
+func disableColor(t *testing.T) {
+	enabled, reset, bold := colorEnabled, Reset, Bold
+	DisableColor()
+	GenerateColors(false)
+	t.Cleanup(func() {
+		colorEnabled, Reset, Bold = enabled, reset, bold
+		GenerateColors(false)
+	})
+}
+
+// renderTestListing prints a listing without colors and returns the output.
+func renderTestListing(t *testing.T, opts RenderOptions, listing *Listing) string {
+	t.Helper()
+	disableColor(t)
+	var out bytes.Buffer
+	r := NewRenderer(&out, opts)
+	r.Listing(listing)
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func findEntry(t *testing.T, listing *Listing, name string) *Entry {
+	t.Helper()
+	for _, entry := range listing.Entries() {
+		if entry.Basename == name {
+			return entry
+		}
+	}
+	t.Fatalf("%s isn't in the listing", name)
+	return nil
+}
+
+func TestRenderLinkWithoutTargetInfo(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Symlink(filepath.Join("secret", "x"), filepath.Join(dir, "lnk")); err != nil {
+		t.Skip("can't make symlinks:", err)
+	}
+	listing := listTestDir(t, Options{}, dir)
+	// what getLinkInfo leaves behind when the target can't be stat'ed for lack of permission
+	entry := findEntry(t, listing, "lnk")
+	entry.Link = &LinkInfo{Path: filepath.Join("secret", "x")}
+
+	for _, opts := range []RenderOptions{{}, {Links: true}, {NerdFont: true, Links: true}, {JSON: true}} {
+		out := renderTestListing(t, opts, listing)
+		if !strings.Contains(out, "lnk") {
+			t.Errorf("%+v: the link is missing from %q", opts, out)
+		}
+	}
+}
+
+func TestListLinkIntoUnreadableFolder(t *testing.T) {
+	if os.Getuid() == 0 {
+		t.Skip("root can read every folder")
+	}
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "secret")
+	if err := os.Mkdir(secret, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("secret", "x"), filepath.Join(dir, "lnk")); err != nil {
+		t.Skip("can't make symlinks:", err)
+	}
+	if err := os.Chmod(secret, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(secret, 0o755) })
+
+	listing := listTestDir(t, Options{}, dir)
+	if link := findEntry(t, listing, "lnk").Link; link.Info != nil || link.Broken {
+		t.Fatalf("expected a link that is neither resolved nor broken, got %+v", link)
+	}
+	out := renderTestListing(t, RenderOptions{Links: true}, listing)
+	if !strings.Contains(out, "lnk") {
+		t.Errorf("the link is missing from %q", out)
+	}
+}
+
+func TestTotal(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "small"), "x")
+	writeTestFile(t, filepath.Join(dir, "big"), strings.Repeat("x", 100000))
+	writeTestFile(t, filepath.Join(dir, "sub", "inside"), strings.Repeat("x", 50000))
+
+	// folders only count their own blocks, even when their sizes are added up
+	var ownSize int64
+	for _, name := range []string{"small", "big", "sub"} {
+		info, err := os.Lstat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ownSize += diskSize(info)
+	}
+	tests := []struct {
+		opts  RenderOptions
+		total string
+	}{
+		{RenderOptions{}, ""},
+		{RenderOptions{Perms: true}, ""},
+		{RenderOptions{Blocks: true, JSON: true}, ""},
+		{RenderOptions{Blocks: true}, fmt.Sprintf("total %d\n", (ownSize+1023)/1024)},
+		{RenderOptions{Blocks: true, BlockSize: 512}, fmt.Sprintf("total %d\n", (ownSize+511)/512)},
+		{RenderOptions{Columns: []Column{ColumnBlocks, ColumnName}}, fmt.Sprintf("total %d\n",
+			(ownSize+1023)/1024)},
+	}
+	for _, opts := range []Options{{}, {DirSize: true}} {
+		listing := listTestDir(t, opts, dir)
+		for i, test := range tests {
+			disableColor(t)
+			var out bytes.Buffer
+			NewRenderer(&out, test.opts).Total(listing)
+			if out.String() != test.total {
+				t.Errorf("test %d with DirSize %v: %q, want %q", i, opts.DirSize, out.String(), test.total)
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package lsgo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/willf/pad"
+)
+
+// SizeStyle is how the sizes of files are written.
+type SizeStyle int
+
+const (
+	// powers of 1024, like 1.50K
+	SizeIEC SizeStyle = iota
+	// powers of 1000, like 1.54k
+	SizeSI
+	// the exact number of bytes, with thousands separators
+	SizeBytes
+	// the number of blocks used on disk, like `ls -s`
+	SizeBlocks
+)
+
+var (
+	// Prefixes for metric units.
+	sizeUnits   = []string{"B", "K", "M", "G", "T", "P", "E"}
+	siSizeUnits = []string{"B", "k", "M", "G", "T", "P", "E"}
+	// The width of a size in the IEC and SI styles, e.g. "1000.0K".
+	unitSizeWidth = 7
+
+	// SizeScale is the smallest size in bytes that gets each color in SizeColor. Sizes are colored the same
+	// no matter which style they are written in.
+	SizeScale = map[string]int64{
+		"B": 0,
+		"K": 1 << 10,
+		"M": 1 << 20,
+		"G": 1 << 30,
+		"T": 1 << 40,
+		"P": 1 << 50,
+		"E": 1 << 60,
+	}
+)
+
+// sizeText writes the size of an entry in the chosen style.
+func (r *Renderer) sizeText(entry *Entry) string {
+	switch r.opts.SizeStyle {
+	case SizeSI:
+		return unitSize(entry.Size, 1000, siSizeUnits)
+	case SizeBytes:
+		return thousands(entry.Size)
+	case SizeBlocks:
+		return thousands(r.blockCount(entry.DiskSize))
+	}
+	return unitSize(entry.Size, 1024, sizeUnits)
+}
+
+// blockCount is how many blocks of RenderOptions.BlockSize it takes to hold a size, rounding up.
+func (r *Renderer) blockCount(diskSize int64) int64 {
+	blockSize := r.opts.BlockSize
+	if blockSize <= 0 {
+		blockSize = 1024
+	}
+	return (diskSize + blockSize - 1) / blockSize
+}
+
+// sizeColor colors the size of an entry by how big it is. The blocks are colored by the space on disk.
+func (r *Renderer) sizeColor(entry *Entry) string {
+	if r.opts.SizeStyle == SizeBlocks {
+		return sizeScaleColor(entry.DiskSize)
+	}
+	return sizeScaleColor(entry.Size)
+}
+
+// unitSize writes a size with two decimals and the largest unit that keeps it below the base, e.g. "1.50K".
+// A size that rounds up to the base, like 999,999 bytes in powers of 1000, is written in the next unit instead.
+func unitSize(size int64, base float64, units []string) string {
+	sizeFloat := float64(size)
+	for i, unit := range units {
+		unitBase := math.Pow(base, float64(i))
+		if sizeFloat < unitBase*base || i == len(units)-1 {
+			var sizeStr string
+			if i == 0 {
+				sizeStr = strconv.FormatInt(size, 10)
+			} else {
+				value := sizeFloat / unitBase
+				if value < 1000 {
+					sizeStr = fmt.Sprintf("%.2f", value)
+				} else {
+					sizeStr = fmt.Sprintf("%.1f", value)
+				}
+				if rounded, _ := strconv.ParseFloat(sizeStr, 64); rounded >= base && i < len(units)-1 {
+					continue
+				}
+			}
+			return pad.Left(sizeStr, unitSizeWidth-len(unit), " ") + unit
+		}
+	}
+	return strconv.FormatInt(size, 10)
+}
+
+// thousands writes a number with commas between each group of 3 digits, e.g. "1,234,567".
+func thousands(num int64) string {
+	digits := strconv.FormatInt(num, 10)
+	sign := ""
+	if num < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	groups := []string{}
+	for len(digits) > 3 {
+		groups = append([]string{digits[len(digits)-3:]}, groups...)
+		digits = digits[:len(digits)-3]
+	}
+	return sign + strings.Join(append([]string{digits}, groups...), ",")
+}
+
+// sizeScaleColor picks the color of the largest unit in SizeScale that the size reaches.
+func sizeScaleColor(size int64) string {
+	color := ""
+	for _, unit := range sizeUnits {
+		threshold, hasThreshold := SizeScale[unit]
+		if hasThreshold && size >= threshold {
+			color = SizeColor[unit]
+		}
+	}
+	return color
+}
+
+// ParseSize reads a size in bytes or with a unit, e.g. "1500", "512B", or "100K".
+func ParseSize(str string) (int64, error) {
+	str = strings.TrimSpace(str)
+	numStr := str
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(strings.ToUpper(str), unit) {
+			if unit != "B" {
+				multiplier = SizeScale[unit]
+			}
+			numStr = str[:len(str)-1]
+			break
+		}
+	}
+	num, err := strconv.ParseFloat(strings.TrimSpace(numStr), 64)
+	if err != nil || num < 0 {
+		return 0, fmt.Errorf("invalid size %q", str)
+	}
+	return int64(num * float64(multiplier)), nil
+}
@@ -0,0 +1,108 @@
+package lsgo
+
+import (
+	"testing"
+)
+
+func TestUnitSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		iec  string
+		si   string
+	}{
+		{0, "     0B", "     0B"},
+		{999, "   999B", "   999B"},
+		{1000, "  1000B", "  1.00k"},
+		{1023, "  1023B", "  1.02k"},
+		{1024, "  1.00K", "  1.02k"},
+		{1536, "  1.50K", "  1.54k"},
+		{999994, "976.56K", "999.99k"},
+		{999999, "976.56K", "  1.00M"},
+		{1023 * 1024, "1023.0K", "  1.05M"},
+		{1048575, "  1.00M", "  1.05M"},
+		{1 << 30, "  1.00G", "  1.07G"},
+		{1 << 62, "  4.00E", "  4.61E"},
+	}
+	for _, test := range tests {
+		if iec := unitSize(test.size, 1024, sizeUnits); iec != test.iec {
+			t.Errorf("unitSize(%d, 1024) = %q, want %q", test.size, iec, test.iec)
+		}
+		if si := unitSize(test.size, 1000, siSizeUnits); si != test.si {
+			t.Errorf("unitSize(%d, 1000) = %q, want %q", test.size, si, test.si)
+		}
+	}
+}
+
+func TestThousands(t *testing.T) {
+	tests := []struct {
+		num int64
+		str string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{-1000, "-1,000"},
+		{123456, "123,456"},
+		{1234567, "1,234,567"},
+		{-12345678, "-12,345,678"},
+	}
+	for _, test := range tests {
+		if str := thousands(test.num); str != test.str {
+			t.Errorf("thousands(%d) = %q, want %q", test.num, str, test.str)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		str  string
+		size int64
+	}{
+		{"0", 0},
+		{"1500", 1500},
+		{" 512 ", 512},
+		{"512B", 512},
+		{"512b", 512},
+		{"1K", 1024},
+		{"4k", 4096},
+		{"1.5M", 1536 * 1024},
+		{"100 K", 100 * 1024},
+		{"2G", 2 << 30},
+		{"1E", 1 << 60},
+	}
+	for _, test := range tests {
+		size, err := ParseSize(test.str)
+		if err != nil {
+			t.Errorf("ParseSize(%q) unexpected error: %s", test.str, err)
+		} else if size != test.size {
+			t.Errorf("ParseSize(%q) = %d, want %d", test.str, size, test.size)
+		}
+	}
+
+	for _, str := range []string{"", "B", "K", "big", "-1K", "1X", "1KB"} {
+		if size, err := ParseSize(str); err == nil {
+			t.Errorf("ParseSize(%q) = %d, want an error", str, size)
+		}
+	}
+}
+
+func TestBlockCount(t *testing.T) {
+	tests := []struct {
+		blockSize int64
+		diskSize  int64
+		blocks    int64
+	}{
+		{0, 4096, 4},
+		{1024, 0, 0},
+		{1024, 1, 1},
+		{1024, 4096, 4},
+		{512, 4096, 8},
+		{4096, 4097, 2},
+	}
+	for _, test := range tests {
+		r := NewRenderer(nil, RenderOptions{BlockSize: test.blockSize})
+		if blocks := r.blockCount(test.diskSize); blocks != test.blocks {
+			t.Errorf("blockCount(%d) with blocks of %d = %d, want %d", test.diskSize, test.blockSize, blocks, test.blocks)
+		}
+	}
+}
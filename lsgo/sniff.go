@@ -0,0 +1,192 @@
+package lsgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// How much of a file is read to sniff its type, which covers the magic numbers and a shebang line.
+const sniffLen = 512
+
+// Magic numbers at the start of a file, and the extension that such files usually have.
+var sniffMagic = []struct {
+	magic []byte
+	ext   string
+}{
+	{[]byte("\x89PNG\r\n\x1a\n"), "png"},
+	{[]byte("\xff\xd8\xff"), "jpg"},
+	{[]byte("GIF87a"), "gif"},
+	{[]byte("GIF89a"), "gif"},
+	{[]byte("%PDF-"), "pdf"},
+	{[]byte("PK\x03\x04"), "zip"},
+	{[]byte("\x1f\x8b"), "gz"},
+	{[]byte("BZh"), "bz2"},
+	{[]byte("\xfd7zXZ\x00"), "xz"},
+	{[]byte("7z\xbc\xaf\x27\x1c"), "7z"},
+	{[]byte("SQLite format 3\x00"), "sqlite"},
+	{[]byte("ID3"), "mp3"},
+	{[]byte("OggS"), "ogg"},
+	{[]byte("fLaC"), "flac"},
+	{[]byte("\xca\xfe\xba\xbe"), "class"},
+	{[]byte("MZ"), "exe"},
+	{[]byte("<?xml"), "xml"},
+}
+
+// Magic numbers of ELF and 64-bit Mach-O files, which are only object files if their header says so.
+var (
+	elfMagic   = []byte("\x7fELF")
+	machOMagic = []byte("\xcf\xfa\xed\xfe")
+)
+
+// Interpreters in shebang lines, and the extension of the scripts they run. Version numbers are removed
+// first, so "python3.11" is "python".
+var sniffInterpreters = map[string]string{
+	"python":  "py",
+	"node":    "js",
+	"nodejs":  "js",
+	"deno":    "js",
+	"bun":     "js",
+	"sh":      "sh",
+	"bash":    "sh",
+	"dash":    "sh",
+	"zsh":     "sh",
+	"ksh":     "sh",
+	"fish":    "sh",
+	"awk":     "sh",
+	"ruby":    "rb",
+	"perl":    "pl",
+	"php":     "php",
+	"lua":     "lua",
+	"rscript": "r",
+}
+
+var interpreterVersion = regexp.MustCompile(`[\d.-]+$`)
+
+// hasKnownType checks whether the name or extension of a file already has a color or an icon, in which case
+// it doesn't need to be sniffed.
+func hasKnownType(basename, ext string) bool {
+	key := strings.ToLower(ext)
+	if alias, hasAlias := FileAliases[key]; hasAlias {
+		key = alias
+	}
+	if _, hasColor := FileColor[key]; hasColor {
+		return true
+	}
+	fullName := basename
+	if ext != "" {
+		fullName += "." + ext
+	}
+	fullName = strings.ToLower(fullName)
+	if _, hasAlias := aliases[fullName]; hasAlias {
+		return true
+	}
+	_, hasIcon := icons[fullName]
+	return hasIcon
+}
+
+// sniffExt reads the start of a file to guess which extension it should have, from a magic number or a
+// shebang line. It returns "" if the contents aren't recognized.
+func sniffExt(absPath string) (string, error) {
+	file, err := os.Open(absPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	head = head[:n]
+
+	// executables and shared libraries are left to the usual executable colors
+	if bytes.HasPrefix(head, elfMagic) || bytes.HasPrefix(head, machOMagic) {
+		if isObjectFile(head) {
+			return "o", nil
+		}
+		return "", nil
+	}
+	for _, magic := range sniffMagic {
+		if bytes.HasPrefix(head, magic.magic) {
+			return magic.ext, nil
+		}
+	}
+	// the magic numbers that aren't at the start
+	if len(head) >= 262 && bytes.Equal(head[257:262], []byte("ustar")) {
+		return "tar", nil
+	}
+	if len(head) >= 12 && bytes.Equal(head[:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WEBP")) {
+		return "webp", nil
+	}
+	if len(head) >= 12 && bytes.Equal(head[4:8], []byte("ftyp")) {
+		return "mp4", nil
+	}
+
+	if bytes.HasPrefix(head, []byte("#!")) {
+		return shebangExt(head), nil
+	}
+	if isDockerfile(head) {
+		return "dockerfile", nil
+	}
+	return "", nil
+}
+
+// isObjectFile checks the type in an ELF or Mach-O header for a relocatable object, like a .o file.
+func isObjectFile(head []byte) bool {
+	if bytes.HasPrefix(head, elfMagic) {
+		if len(head) < 18 {
+			return false
+		}
+		// e_type is ET_REL, in the byte order from EI_DATA
+		if head[5] == 2 {
+			return binary.BigEndian.Uint16(head[16:18]) == 1
+		}
+		return binary.LittleEndian.Uint16(head[16:18]) == 1
+	}
+	// filetype is MH_OBJECT
+	return len(head) >= 16 && binary.LittleEndian.Uint32(head[12:16]) == 1
+}
+
+// shebangExt finds the interpreter in a shebang line, like "#!/bin/bash" or "#!/usr/bin/env -S python3 -u".
+func shebangExt(head []byte) string {
+	line := string(head[2:])
+	if end := strings.IndexByte(line, '\n'); end >= 0 {
+		line = line[:end]
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := filepath.Base(fields[0])
+	// env runs the first argument that isn't an option or a variable
+	if interpreter == "env" {
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+				interpreter = filepath.Base(field)
+				break
+			}
+		}
+	}
+	interpreter = interpreterVersion.ReplaceAllString(strings.ToLower(interpreter), "")
+	return sniffInterpreters[interpreter]
+}
+
+// isDockerfile checks whether the first instruction, after any comments and blank lines, is a FROM.
+func isDockerfile(head []byte) bool {
+	for _, line := range strings.Split(string(head), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		return len(fields) >= 2 && strings.EqualFold(fields[0], "FROM")
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package lsgo
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// elfHeader builds the start of an ELF file with an e_type, in little- or big-endian byte order.
+func elfHeader(eType uint16, bigEndian bool) string {
+	header := []byte("\x7fELF\x02\x01\x01" + strings.Repeat("\x00", 9) + "\x00\x00\x3e\x00")
+	if bigEndian {
+		header[5] = 2
+		header[16], header[17] = byte(eType>>8), byte(eType)
+	} else {
+		header[16], header[17] = byte(eType), byte(eType>>8)
+	}
+	return string(header)
+}
+
+func TestSniffExt(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		contents string
+		ext      string
+	}{
+		{"\x89PNG\r\n\x1a\n....", "png"},
+		{"\xff\xd8\xff\xe0", "jpg"},
+		{"GIF89a", "gif"},
+		{"%PDF-1.7\n", "pdf"},
+		{"PK\x03\x04", "zip"},
+		{"\x1f\x8b\x08", "gz"},
+		{"SQLite format 3\x00", "sqlite"},
+		{"\xca\xfe\xba\xbe", "class"},
+		{"MZ\x90\x00", "exe"},
+		{"<?xml version=\"1.0\"?>", "xml"},
+		{strings.Repeat("\x00", 257) + "ustar\x0000", "tar"},
+		{"RIFF\x00\x00\x00\x00WEBPVP8 ", "webp"},
+		{"\x00\x00\x00\x18ftypmp42", "mp4"},
+		{elfHeader(1, false), "o"},
+		{elfHeader(1, true), "o"},
+		{elfHeader(2, false), ""},
+		{elfHeader(3, false), ""},
+		{elfHeader(2, true), ""},
+		{"\x7fELF", ""},
+		{"\xcf\xfa\xed\xfe\x07\x00\x00\x01\x03\x00\x00\x00\x01\x00\x00\x00", "o"},
+		{"\xcf\xfa\xed\xfe\x07\x00\x00\x01\x03\x00\x00\x00\x02\x00\x00\x00", ""},
+		{"#!/bin/bash\necho hi\n", "sh"},
+		{"#!/usr/bin/env python3\n", "py"},
+		{"# syntax=docker/dockerfile:1\n\nFROM alpine\n", "dockerfile"},
+		{"just some text\n", ""},
+	}
+	for i, test := range tests {
+		filePath := filepath.Join(dir, strings.Repeat("f", i+1))
+		writeTestFile(t, filePath, test.contents)
+		ext, err := sniffExt(filePath)
+		if err != nil {
+			t.Errorf("sniffExt(%q) unexpected error: %s", test.contents, err)
+		} else if ext != test.ext {
+			t.Errorf("sniffExt(%q) = %q, want %q", test.contents, ext, test.ext)
+		}
+	}
+
+	if _, err := sniffExt(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("sniffExt of a missing file didn't give an error")
+	}
+}
+
+func TestShebangExt(t *testing.T) {
+	tests := []struct {
+		line string
+		ext  string
+	}{
+		{"#!/bin/sh", "sh"},
+		{"#! /bin/bash -e", "sh"},
+		{"#!/usr/bin/python3.11", "py"},
+		{"#!/usr/bin/env node", "js"},
+		{"#!/usr/bin/env -S python3 -u", "py"},
+		{"#!/usr/bin/env LANG=C perl -w", "pl"},
+		{"#!/usr/local/bin/Rscript", "r"},
+		{"#!/usr/bin/env", ""},
+		{"#!", ""},
+		{"#!/usr/bin/unknown", ""},
+		{"#!/bin/zsh\nexec python", "sh"},
+	}
+	for _, test := range tests {
+		if ext := shebangExt([]byte(test.line)); ext != test.ext {
+			t.Errorf("shebangExt(%q) = %q, want %q", test.line, ext, test.ext)
+		}
+	}
+}
+
+func TestIsDockerfile(t *testing.T) {
+	tests := []struct {
+		contents     string
+		isDockerfile bool
+	}{
+		{"FROM golang:1.22", true},
+		{"from alpine AS build", true},
+		{"\n# comment\n  FROM scratch\n", true},
+		{"FROM", false},
+		{"RUN make\nFROM alpine", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if isDockerfile := isDockerfile([]byte(test.contents)); isDockerfile != test.isDockerfile {
+			t.Errorf("isDockerfile(%q) = %v, want %v", test.contents, isDockerfile, test.isDockerfile)
+		}
+	}
+}
+
+func TestListSniffedFiles(t *testing.T) {
+	GenerateColors(false)
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "script"), "#!/usr/bin/env python3\n")
+	// files are only sniffed if their names don't already say what they are, and they aren't empty
+	writeTestFile(t, filepath.Join(dir, "notes.md"), "#!/bin/sh\n")
+	writeTestFile(t, filepath.Join(dir, "empty"), "")
+
+	expected := map[string]string{"script": "py", "notes": "", "empty": ""}
+	for _, sniff := range []bool{false, true} {
+		for _, entry := range listTestDir(t, Options{Sniff: sniff}, dir).Files {
+			want := ""
+			if sniff {
+				want = expected[entry.Basename]
+			}
+			if entry.SniffedExt != want {
+				t.Errorf("sniffing %v: %s looks like %q, want %q", sniff, entry.Basename, entry.SniffedExt,
+					want)
+			}
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package lsgo
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SortField is something entries can be sorted by.
+type SortField int
+
+const (
+	// the name byte by byte, which is the order the folders are read in
+	SortByName SortField = iota
+	// the name with runs of digits by their value and letters regardless of case, so "file2" is before "file10"
+	SortByNatural
+	// the name like version numbers, the same as `ls -v`, so "v1.9.0" is before "v1.10.0"
+	SortByVersion
+	// the size, smallest first. Folders are sorted by what they contain when Options.DirSize is set
+	SortBySize
+	// the time chosen by Options.TimeField, oldest first
+	SortByTime
+	// the extension, with files without an extension first and hidden files last
+	SortByKind
+)
+
+// The names of the sort fields in a sort spec, as in "kind,size,-time,name".
+var sortFieldNames = map[string]SortField{
+	"name":    SortByName,
+	"natural": SortByNatural,
+	"version": SortByVersion,
+	"size":    SortBySize,
+	"time":    SortByTime,
+	"kind":    SortByKind,
+}
+
+// SortKey is a field to sort by, and which way.
+type SortKey struct {
+	Field   SortField
+	Reverse bool
+}
+
+// ParseSortKeys reads a comma-separated list of fields to sort by, most important first, like
+// "kind,size,-time,name". A "-" in front of a field reverses it.
+func ParseSortKeys(spec string) ([]SortKey, error) {
+	keys := []SortKey{}
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		key := SortKey{}
+		if strings.HasPrefix(name, "-") {
+			key.Reverse = true
+			name = name[1:]
+		}
+		field, isField := sortFieldNames[name]
+		if !isField {
+			return nil, fmt.Errorf("unknown sort key %q, expected name, natural, version, size, time, or kind", name)
+		}
+		key.Field = field
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Compares 2 entries by one field, returning a negative number if a comes first and a positive one if b does.
+var sortComparators = map[SortField]func(a, b *Entry) int{
+	SortByName: func(a, b *Entry) int {
+		return strings.Compare(a.Info.Name(), b.Info.Name())
+	},
+	SortByNatural: func(a, b *Entry) int {
+		return naturalCompare(a.Info.Name(), b.Info.Name())
+	},
+	SortByVersion: func(a, b *Entry) int {
+		return versionCompare(a.Info.Name(), b.Info.Name())
+	},
+	SortBySize: func(a, b *Entry) int {
+		return compareInts(a.Size, b.Size)
+	},
+	SortByTime: func(a, b *Entry) int {
+		return compareInts(a.Time.UnixNano(), b.Time.UnixNano())
+	},
+	SortByKind: func(a, b *Entry) int {
+		return strings.Compare(kindOf(a), kindOf(b))
+	},
+}
+
+// sortByKeys sorts entries by each key in turn, and then by name so the order is always the same. The sort
+// is stable, so the entries that are equal by every key keep the order they were read in.
+func sortByKeys(entries []*Entry, keys []SortKey) {
+	keys = append(keys[:len(keys):len(keys)], SortKey{Field: SortByName})
+	sort.SliceStable(entries, func(i, j int) bool {
+		for _, key := range keys {
+			c := sortComparators[key.Field](entries[i], entries[j])
+			if key.Reverse {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+}
+
+// kindOf is what entries are grouped by when sorting by kind.
+func kindOf(entry *Entry) string {
+	if entry.IsHidden() {
+		return "." + entry.Ext
+	} else if entry.Ext == "" {
+		return "0"
+	}
+	return entry.Ext
+}
+
+func compareInts(a, b int64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// naturalCompare compares runs of digits by their value and everything else one letter at a time, ignoring
+// case. Names that only differ by case or leading zeros fall back to comparing byte by byte, so the order
+// never depends on the order the names were read in.
+func naturalCompare(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			// skip the leading zeros, then a longer number is bigger, otherwise compare the digits
+			for i < len(a)-1 && a[i] == '0' && isDigit(a[i+1]) {
+				i++
+			}
+			for j < len(b)-1 && b[j] == '0' && isDigit(b[j+1]) {
+				j++
+			}
+			endA, endB := i, j
+			for endA < len(a) && isDigit(a[endA]) {
+				endA++
+			}
+			for endB < len(b) && isDigit(b[endB]) {
+				endB++
+			}
+			if endA-i != endB-j {
+				return (endA - i) - (endB - j)
+			}
+			if c := strings.Compare(a[i:endA], b[j:endB]); c != 0 {
+				return c
+			}
+			i, j = endA, endB
+			continue
+		}
+		runeA, sizeA := utf8.DecodeRuneInString(a[i:])
+		runeB, sizeB := utf8.DecodeRuneInString(b[j:])
+		lowerA, lowerB := unicode.ToLower(runeA), unicode.ToLower(runeB)
+		if lowerA != lowerB {
+			return int(lowerA) - int(lowerB)
+		}
+		i += sizeA
+		j += sizeB
+	}
+	if remaining := (len(a) - i) - (len(b) - j); remaining != 0 {
+		return remaining
+	}
+	return strings.Compare(a, b)
+}
+
+// Matches the file extensions at the end of a name, like ".tar.gz", which are set aside when comparing
+// version numbers so that "foo-1.2.tar.gz" comes before "foo-1.2.1.tar.gz".
+var versionSuffix = regexp.MustCompile(`(\.[A-Za-z~][A-Za-z0-9~]*)*$`)
+
+// versionCompare orders names like GNU's filevercmp, which is what `ls -v` uses. Hidden files come first,
+// then the names are compared without their extensions, and then with them.
+func versionCompare(a, b string) int {
+	if a == b {
+		return 0
+	}
+	for _, special := range []string{"", ".", ".."} {
+		if a == special {
+			return -1
+		}
+		if b == special {
+			return 1
+		}
+	}
+	hiddenA, hiddenB := a[0] == '.', b[0] == '.'
+	if hiddenA != hiddenB {
+		if hiddenA {
+			return -1
+		}
+		return 1
+	}
+	if hiddenA {
+		a, b = a[1:], b[1:]
+	}
+
+	prefixA := a[:versionSuffix.FindStringIndex(a)[0]]
+	prefixB := b[:versionSuffix.FindStringIndex(b)[0]]
+	if c := verrevcmp(prefixA, prefixB); c != 0 {
+		return c
+	}
+	if c := verrevcmp(a, b); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// verrevcmp is the Debian version comparison. Runs of digits are compared by their value, and everything else
+// one character at a time, with "~" before the end of the string, which is before letters, which are before
+// any other character.
+func verrevcmp(a, b string) int {
+	order := func(s string, i int) int {
+		switch {
+		case i >= len(s) || isDigit(s[i]):
+			return 0
+		case ('a' <= s[i] && s[i] <= 'z') || ('A' <= s[i] && s[i] <= 'Z'):
+			return int(s[i])
+		case s[i] == '~':
+			return -1
+		}
+		return int(s[i]) + 256
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			orderA, orderB := order(a, i), order(b, j)
+			if orderA != orderB {
+				return orderA - orderB
+			}
+			i++
+			j++
+		}
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		firstDiff := 0
+		for i < len(a) && j < len(b) && isDigit(a[i]) && isDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isDigit(b[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return firstDiff
+		}
+	}
+	return 0
+}
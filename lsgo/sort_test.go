@@ -0,0 +1,116 @@
+package lsgo
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseSortKeys(t *testing.T) {
+	tests := []struct {
+		spec string
+		keys []SortKey
+	}{
+		{"name", []SortKey{{SortByName, false}}},
+		{"-size", []SortKey{{SortBySize, true}}},
+		{"kind, size,-time,name", []SortKey{
+			{SortByKind, false}, {SortBySize, false}, {SortByTime, true}, {SortByName, false},
+		}},
+		{"natural,-version", []SortKey{{SortByNatural, false}, {SortByVersion, true}}},
+	}
+	for _, test := range tests {
+		keys, err := ParseSortKeys(test.spec)
+		if err != nil {
+			t.Errorf("ParseSortKeys(%q) unexpected error: %s", test.spec, err)
+		} else if !reflect.DeepEqual(keys, test.keys) {
+			t.Errorf("ParseSortKeys(%q) = %v, want %v", test.spec, keys, test.keys)
+		}
+	}
+
+	for _, spec := range []string{"", "date", "name,", "--size", "+time"} {
+		if keys, err := ParseSortKeys(spec); err == nil {
+			t.Errorf("ParseSortKeys(%q) = %v, want an error", spec, keys)
+		}
+	}
+}
+
+func TestListingSortedByKeys(t *testing.T) {
+	dir := t.TempDir()
+	for name, size := range map[string]int{"a.txt": 3, "b.txt": 1, "c.go": 2, "d": 5, "e.go": 2} {
+		writeTestFile(t, filepath.Join(dir, name), strings.Repeat("x", size))
+	}
+	tests := []struct {
+		spec  string
+		names []string
+	}{
+		{"name", []string{"a.txt", "b.txt", "c.go", "d", "e.go"}},
+		{"-name", []string{"e.go", "d", "c.go", "b.txt", "a.txt"}},
+		{"size", []string{"b.txt", "c.go", "e.go", "a.txt", "d"}},
+		// ties are broken by the next key, and then by name
+		{"-size", []string{"d", "a.txt", "c.go", "e.go", "b.txt"}},
+		{"kind,size", []string{"d", "c.go", "e.go", "b.txt", "a.txt"}},
+		{"kind,-name", []string{"d", "e.go", "c.go", "b.txt", "a.txt"}},
+	}
+	for _, test := range tests {
+		keys, err := ParseSortKeys(test.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := []string{}
+		for _, entry := range listTestDir(t, Options{Sort: keys}, dir).Entries() {
+			names = append(names, entry.Filename())
+		}
+		if !reflect.DeepEqual(names, test.names) {
+			t.Errorf("sorted by %s: %q, want %q", test.spec, names, test.names)
+		}
+	}
+}
+
+// sortedBy sorts a copy of names with a comparison function.
+func sortedBy(names []string, compare func(a, b string) int) []string {
+	sorted := append([]string{}, names...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return compare(sorted[i], sorted[j]) < 0
+	})
+	return sorted
+}
+
+func TestNaturalCompare(t *testing.T) {
+	tests := []struct {
+		names  []string
+		sorted []string
+	}{
+		{[]string{"file10", "file2", "file1"}, []string{"file1", "file2", "file10"}},
+		{[]string{"b", "A", "a", "B"}, []string{"A", "a", "B", "b"}},
+		{[]string{"x010", "x9", "x10"}, []string{"x9", "x010", "x10"}},
+		{[]string{"a1b", "a1", "a"}, []string{"a", "a1", "a1b"}},
+		{[]string{"img12.png", "img2.png", "img1.jpg"}, []string{"img1.jpg", "img2.png", "img12.png"}},
+		{[]string{"Émile", "émile", "Zoe"}, []string{"Zoe", "Émile", "émile"}},
+	}
+	for _, test := range tests {
+		if sorted := sortedBy(test.names, naturalCompare); !reflect.DeepEqual(sorted, test.sorted) {
+			t.Errorf("natural sort of %q = %q, want %q", test.names, sorted, test.sorted)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	tests := []struct {
+		names  []string
+		sorted []string
+	}{
+		{[]string{"v1.10.0", "v1.9.0", "v1.9.1"}, []string{"v1.9.0", "v1.9.1", "v1.10.0"}},
+		{[]string{"foo-1.2.1.tar.gz", "foo-1.2.tar.gz"}, []string{"foo-1.2.tar.gz", "foo-1.2.1.tar.gz"}},
+		{[]string{"b", ".hidden", "a"}, []string{".hidden", "a", "b"}},
+		{[]string{"1.0", "1.0~rc1", "1.0a"}, []string{"1.0~rc1", "1.0", "1.0a"}},
+		{[]string{"a-2", "a-02", "a-1"}, []string{"a-1", "a-02", "a-2"}},
+		{[]string{"B", "a"}, []string{"B", "a"}},
+	}
+	for _, test := range tests {
+		if sorted := sortedBy(test.names, versionCompare); !reflect.DeepEqual(sorted, test.sorted) {
+			t.Errorf("version sort of %q = %q, want %q", test.names, sorted, test.sorted)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+//go:build darwin
+
+package lsgo
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileTimes reads the access, status change, and birth times from the stat info.
+func fileTimes(info os.FileInfo, absPath string, withBirth bool) (accessed, changed, created time.Time) {
+	statT := info.Sys().(*syscall.Stat_t)
+	accessed = time.Unix(statT.Atimespec.Sec, statT.Atimespec.Nsec)
+	changed = time.Unix(statT.Ctimespec.Sec, statT.Ctimespec.Nsec)
+	created = time.Unix(statT.Birthtimespec.Sec, statT.Birthtimespec.Nsec)
+	return
+}
@@ -0,0 +1,28 @@
+//go:build linux
+
+package lsgo
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// fileTimes reads the access and status change times from the stat info. The birth time isn't part of
+// stat on Linux, so it's only read with statx if it's asked for, and stays zero if the filesystem doesn't
+// record it.
+func fileTimes(info os.FileInfo, absPath string, withBirth bool) (accessed, changed, created time.Time) {
+	statT := info.Sys().(*syscall.Stat_t)
+	accessed = time.Unix(int64(statT.Atim.Sec), int64(statT.Atim.Nsec))
+	changed = time.Unix(int64(statT.Ctim.Sec), int64(statT.Ctim.Nsec))
+	if withBirth {
+		statx := unix.Statx_t{}
+		err := unix.Statx(unix.AT_FDCWD, absPath, unix.AT_SYMLINK_NOFOLLOW, unix.STATX_BTIME, &statx)
+		if err == nil && statx.Mask&unix.STATX_BTIME != 0 {
+			created = time.Unix(statx.Btime.Sec, int64(statx.Btime.Nsec))
+		}
+	}
+	return
+}
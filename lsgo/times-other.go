@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !windows
+
+package lsgo
+
+import (
+	"os"
+	"time"
+)
+
+// fileTimes isn't supported on this platform, so the times are left as zero, which shows up as unknown.
+func fileTimes(info os.FileInfo, absPath string, withBirth bool) (accessed, changed, created time.Time) {
+	return
+}
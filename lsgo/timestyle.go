@@ -0,0 +1,161 @@
+package lsgo
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/willf/pad"
+)
+
+// TimeColor is how timestamps are colored.
+type TimeColor int
+
+const (
+	// the time of day gets darker towards midnight and lighter towards noon
+	TimeColorHour TimeColor = iota
+	// all the same color
+	TimeColorPlain
+	// the whole timestamp is colored by how recent it is
+	TimeColorAge
+)
+
+var (
+	// Uses the "reference time"
+	// https://golang.org/pkg/time/#Time.Format
+	dateFormat = "02.Jan'06"
+	timeFormat = "15:04"
+	// The layouts of each time style, split into the date and the time of day so they can be colored
+	// separately. The "iso" style is special, since it depends on how old the time is.
+	timeStyleLayouts = map[string][2]string{
+		"":         {dateFormat, timeFormat},
+		"long-iso": {"2006-01-02", "15:04"},
+	}
+	// Like time.RFC3339Nano, but with a fixed width.
+	fullTimeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+	// The longest relative time is like "in 59 minutes" or "11 months ago".
+	relativeTimeWidth = len("59 minutes ago")
+)
+
+// IsTimeStyle checks whether a style can be used for RenderOptions.TimeStyle.
+func IsTimeStyle(style string) bool {
+	_, hasLayout := timeStyleLayouts[style]
+	return hasLayout || style == "relative" || style == "iso" || style == "full" || (strings.HasPrefix(style, "+") && len(style) > 1)
+}
+
+// timeString formats a timestamp in the chosen style.
+func (r *Renderer) timeString(t time.Time) string {
+	dateStr, clockStr := r.formatTime(t)
+	// the time isn't known, e.g. the creation time on a filesystem that doesn't keep it
+	if t.IsZero() {
+		width := utf8.RuneCountInString(dateStr) + utf8.RuneCountInString(clockStr)
+		if dateStr != "" && clockStr != "" {
+			width++
+		}
+		return FgGray(8) + pad.Left("-", width, " ")
+	}
+
+	dateColor, clockColor := r.timeColors(t)
+	colored := []string{}
+	if dateStr != "" {
+		colored = append(colored, dateColor+dateStr)
+	}
+	if clockStr != "" {
+		colored = append(colored, clockColor+clockStr)
+	}
+	return strings.Join(colored, " ")
+}
+
+// formatTime splits a timestamp into the date and the time of day, so they can be colored separately. The
+// styles that can't be split only have a time of day. Zero times are formatted like any other time, to
+// know how wide they should be.
+func (r *Renderer) formatTime(t time.Time) (string, string) {
+	style := r.opts.TimeStyle
+	switch {
+	case style == "relative":
+		return "", pad.Left(relativeTime(t, r.start), relativeTimeWidth, " ")
+	case style == "iso":
+		// like GNU ls, the year is only shown instead of the time for times that are more than 6 months old
+		if r.start.Sub(t) < 182*24*time.Hour && !t.IsZero() {
+			return t.Format("01-02"), t.Format("15:04")
+		}
+		return pad.Right(t.Format("2006-01-02"), len("01-02 15:04"), " "), ""
+	case style == "full":
+		return "", t.Format(fullTimeFormat)
+	case strings.HasPrefix(style, "+"):
+		return "", t.Format(style[1:])
+	}
+	layouts := timeStyleLayouts[style]
+	return t.Format(layouts[0]), t.Format(layouts[1])
+}
+
+// timeColors returns the colors for the date and for the time of day.
+func (r *Renderer) timeColors(t time.Time) (string, string) {
+	switch r.opts.TimeColor {
+	case TimeColorPlain:
+		return FgGray(22), FgGray(22)
+	case TimeColorAge:
+		color := ConfigColor["age"][ageName(r.start.Sub(t))]
+		return color, color
+	}
+	hour := t.Hour()
+	// Generate a color based on the hour of the day. darkest at midnight and lightest at noon.
+	timeColor := 14 - int(8*math.Cos(math.Pi*float64(hour)/12))
+	return FgGray(22), FgGray(timeColor)
+}
+
+// ageName buckets the age of a timestamp for the "age" colors. Times in the future count as new.
+func ageName(age time.Duration) string {
+	switch {
+	case age < time.Hour:
+		return "now"
+	case age < 24*time.Hour:
+		return "today"
+	case age < 7*24*time.Hour:
+		return "week"
+	case age < 30*24*time.Hour:
+		return "month"
+	}
+	return "older"
+}
+
+// relativeTime describes how long ago a time was, like "3 hours ago", or how far in the future it is.
+func relativeTime(t time.Time, now time.Time) string {
+	age := now.Sub(t)
+	future := age < 0
+	if future {
+		age = -age
+	}
+	if age < time.Minute {
+		return "just now"
+	}
+
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"week", 7 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, unit := range units {
+		count := int(age / unit.size)
+		if count == 0 {
+			continue
+		}
+		desc := strconv.Itoa(count) + " " + unit.name
+		if count > 1 {
+			desc += "s"
+		}
+		if future {
+			return "in " + desc
+		}
+		return desc + " ago"
+	}
+	return "just now"
+}
@@ -0,0 +1,137 @@
+package lsgo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		age  time.Duration
+		desc string
+	}{
+		{0, "just now"},
+		{59 * time.Second, "just now"},
+		{-30 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{59 * time.Minute, "59 minutes ago"},
+		{-5 * time.Minute, "in 5 minutes"},
+		{time.Hour, "1 hour ago"},
+		{25 * time.Hour, "1 day ago"},
+		{6 * 24 * time.Hour, "6 days ago"},
+		{14 * 24 * time.Hour, "2 weeks ago"},
+		{-14 * 24 * time.Hour, "in 2 weeks"},
+		{60 * 24 * time.Hour, "2 months ago"},
+		{364 * 24 * time.Hour, "12 months ago"},
+		{365 * 24 * time.Hour, "1 year ago"},
+		{3 * 365 * 24 * time.Hour, "3 years ago"},
+	}
+	for _, test := range tests {
+		if desc := relativeTime(now.Add(-test.age), now); desc != test.desc {
+			t.Errorf("relativeTime(%s ago) = %q, want %q", test.age, desc, test.desc)
+		}
+	}
+}
+
+func TestAgeName(t *testing.T) {
+	tests := []struct {
+		age  time.Duration
+		name string
+	}{
+		{-time.Hour, "now"},
+		{0, "now"},
+		{59 * time.Minute, "now"},
+		{time.Hour, "today"},
+		{23 * time.Hour, "today"},
+		{24 * time.Hour, "week"},
+		{7 * 24 * time.Hour, "month"},
+		{29 * 24 * time.Hour, "month"},
+		{30 * 24 * time.Hour, "older"},
+	}
+	for _, test := range tests {
+		if name := ageName(test.age); name != test.name {
+			t.Errorf("ageName(%s) = %q, want %q", test.age, name, test.name)
+		}
+	}
+}
+
+func TestTimeColors(t *testing.T) {
+	GenerateColors(false)
+	start := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		timeColor TimeColor
+		t         time.Time
+		date      string
+		clock     string
+	}{
+		{TimeColorAge, start.Add(-time.Minute), ConfigColor["age"]["now"], ConfigColor["age"]["now"]},
+		{TimeColorAge, start.Add(-3 * 24 * time.Hour), ConfigColor["age"]["week"], ConfigColor["age"]["week"]},
+		{TimeColorAge, start.AddDate(-1, 0, 0), ConfigColor["age"]["older"], ConfigColor["age"]["older"]},
+		{TimeColorPlain, start, FgGray(22), FgGray(22)},
+		// by the hour, darkest at midnight and lightest at noon
+		{TimeColorHour, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), FgGray(22), FgGray(6)},
+		{TimeColorHour, time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), FgGray(22), FgGray(22)},
+	}
+	for _, test := range tests {
+		r := NewRenderer(nil, RenderOptions{TimeColor: test.timeColor})
+		r.start = start
+		if date, clock := r.timeColors(test.t); date != test.date || clock != test.clock {
+			t.Errorf("timeColors(%s) with %d = %q, %q, want %q, %q", test.t, test.timeColor, date, clock,
+				test.date, test.clock)
+		}
+	}
+}
+
+func TestIsTimeStyle(t *testing.T) {
+	tests := []struct {
+		style   string
+		isStyle bool
+	}{
+		{"", true},
+		{"relative", true},
+		{"iso", true},
+		{"long-iso", true},
+		{"full", true},
+		{"+2006", true},
+		{"+", false},
+		{"hour", false},
+		{"locale", false},
+	}
+	for _, test := range tests {
+		if isStyle := IsTimeStyle(test.style); isStyle != test.isStyle {
+			t.Errorf("IsTimeStyle(%q) = %v, want %v", test.style, isStyle, test.isStyle)
+		}
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	start := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	recent := time.Date(2024, 6, 1, 9, 5, 30, 123, time.UTC)
+	old := time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		style string
+		t     time.Time
+		date  string
+		clock string
+	}{
+		{"", recent, "01.Jun'24", "09:05"},
+		{"long-iso", recent, "2024-06-01", "09:05"},
+		{"iso", recent, "06-01", "09:05"},
+		{"iso", old, "2023-01-02 ", ""},
+		{"iso", time.Time{}, "0001-01-01 ", ""},
+		{"full", recent, "", "2024-06-01T09:05:30.000000123Z"},
+		{"+Jan 2", old, "", "Jan 2"},
+		{"relative", recent, "", "   2 weeks ago"},
+		{"relative", start.Add(-time.Hour), "", "    1 hour ago"},
+	}
+	for _, test := range tests {
+		r := NewRenderer(nil, RenderOptions{TimeStyle: test.style})
+		r.start = start
+		date, clock := r.formatTime(test.t)
+		if date != test.date || clock != test.clock {
+			t.Errorf("formatTime(%s) with style %q = %q, %q, want %q, %q", test.t, test.style, date, clock,
+				test.date, test.clock)
+		}
+	}
+}
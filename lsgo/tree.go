@@ -0,0 +1,58 @@
+package lsgo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Box-drawing pieces used to connect each item to its parent in a tree.
+const (
+	treeBranch = "├──"
+	treeLast   = "└──"
+	treePipe   = "│   "
+	treeSpace  = "    "
+)
+
+// Tree prints a listing made by Lister.Tree, with the contents of each folder right below its own entry.
+// It returns the number of files and folders that were printed.
+func (r *Renderer) Tree(listing *Listing) (int, int) {
+	return r.printTree(listing, "")
+}
+
+// The prefix holds the connectors inherited from the parent folders.
+func (r *Renderer) printTree(listing *Listing, prefix string) (int, int) {
+	items, header := r.collectItems(listing)
+	numFiles, numDirs := len(listing.Files), len(listing.Dirs)
+	// the titles are only shown above the whole tree
+	if header != "" && prefix == "" {
+		fmt.Fprintln(r.out, header)
+	}
+
+	color := ConfigColor["tree"]["connector"]
+	for i, item := range items {
+		connector, childPrefix := treeBranch, treePipe
+		if i == len(items)-1 {
+			connector, childPrefix = treeLast, treeSpace
+		}
+		fmt.Fprintln(r.out, item.details+color+prefix+connector+Reset+item.label+item.after)
+		for _, attr := range item.attrs {
+			fmt.Fprintln(r.out, attrIndent(item)+color+prefix+childPrefix+Reset+"  "+attr)
+		}
+
+		children := item.Children
+		if children == nil {
+			continue
+		}
+		if children.Err != nil {
+			// keep the error message lined up with the names when the long columns are shown
+			indent := strings.Repeat(" ", visibleWidth(item.details))
+			fmt.Fprintln(r.out, indent+color+prefix+childPrefix+treeLast+Reset+" "+
+				ConfigColor["folderHeader"]["error"]+children.Err.Error()+Reset)
+			continue
+		}
+		childFiles, childDirs := r.printTree(children, prefix+childPrefix)
+		numFiles += childFiles
+		numDirs += childDirs
+	}
+	return numFiles, numDirs
+}
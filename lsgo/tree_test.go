@@ -0,0 +1,78 @@
+package lsgo
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a/x.txt", "a/b/y", "a/b/z", "c/.hidden", "d.txt"} {
+		writeTestFile(t, filepath.Join(dir, name), "")
+	}
+	disableColor(t)
+
+	tests := []struct {
+		opts     Options
+		tree     string
+		numFiles int
+		numDirs  int
+	}{
+		{Options{}, treeLines(
+			"├── a ",
+			"│   ├── b ",
+			"│   │   ├── y",
+			"│   │   └── z",
+			"│   └── x.txt",
+			"├── c ",
+			"└── d.txt",
+		), 4, 3},
+		{Options{All: true, Group: GroupDirsLast}, treeLines(
+			"├── d.txt",
+			"├── a ",
+			"│   ├── x.txt",
+			"│   └── b ",
+			"│       ├── y",
+			"│       └── z",
+			"└── c ",
+			"    └── .hidden",
+		), 5, 3},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		numFiles, numDirs := NewRenderer(&out, RenderOptions{}).Tree(NewLister(test.opts).Tree(dir))
+		if out.String() != test.tree {
+			t.Errorf("%+v:\n%s\nwant\n%s", test.opts, out.String(), test.tree)
+		}
+		if numFiles != test.numFiles || numDirs != test.numDirs {
+			t.Errorf("%+v: counted %d files and %d folders, want %d and %d",
+				test.opts, numFiles, numDirs, test.numFiles, test.numDirs)
+		}
+	}
+}
+
+func TestTreeLongFormat(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "a", "b", "file"), "")
+	disableColor(t)
+	var out bytes.Buffer
+	NewRenderer(&out, RenderOptions{Perms: true}).Tree(NewLister(Options{}).Tree(dir))
+
+	// the columns stay on the left, and the connectors are indented after them
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines in %q", out.String())
+	}
+	for i, connector := range []string{"└──", "    └──", "        └──"} {
+		if !strings.Contains(lines[i], " "+connector) || strings.Index(lines[i], "└") < 10 {
+			t.Errorf("line %d isn't indented like %q: %q", i, connector, lines[i])
+		}
+	}
+}
+
+// treeLines puts together the expected lines of a tree, which have spaces at the end after folders.
+func treeLines(lines ...string) string {
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,69 @@
+package lsgo
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// The number of workers when Options.Workers isn't set. Reading folders and stat-ing files mostly waits on
+// the disk, or the network, so this can be more than the number of CPUs.
+const defaultWorkers = 16
+
+// forEach calls fn for each index from 0 to n, spreading the calls over whichever workers are free. The
+// calling goroutine takes its share of the calls too, so this never waits for a worker and can be nested.
+func (l *Lister) forEach(n int, fn func(i int)) {
+	next := int64(-1)
+	work := func() {
+		for i := int(atomic.AddInt64(&next, 1)); i < n; i = int(atomic.AddInt64(&next, 1)) {
+			fn(i)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for helpers := 1; helpers < n && l.tryAcquire(); helpers++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer l.release()
+			work()
+		}()
+	}
+	work()
+	wg.Wait()
+}
+
+func (l *Lister) tryAcquire() bool {
+	select {
+	case l.workers <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *Lister) release() {
+	<-l.workers
+}
+
+// pendingListing is a folder that is being read ahead of time by a worker.
+type pendingListing struct {
+	done    chan struct{}
+	listing *Listing
+}
+
+// listAsync starts reading a folder as soon as a worker is free.
+func (l *Lister) listAsync(pathStr string) *pendingListing {
+	pending := pendingListing{done: make(chan struct{})}
+	go func() {
+		l.workers <- struct{}{}
+		defer l.release()
+		pending.listing = l.List(pathStr)
+		close(pending.done)
+	}()
+	return &pending
+}
+
+func (pending *pendingListing) wait() *Listing {
+	<-pending.done
+	return pending.listing
+}
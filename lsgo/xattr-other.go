@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+
+package lsgo
+
+import "errors"
+
+// listXattrs is not supported on this platform.
+func listXattrs(absPath string) []string {
+	return nil
+}
+
+// getXattr is not supported on this platform.
+func getXattr(absPath string, name string) ([]byte, error) {
+	return nil, errors.New("extended attributes are not supported")
+}
@@ -0,0 +1,46 @@
+//go:build linux || darwin
+
+package lsgo
+
+import (
+	"bytes"
+
+	"golang.org/x/sys/unix"
+)
+
+// listXattrs returns the names of the extended attributes of a file, without following symlinks. Any error,
+// most often a filesystem that doesn't support them, is treated as having none.
+func listXattrs(absPath string) []string {
+	size, err := unix.Llistxattr(absPath, nil)
+	if err != nil || size <= 0 {
+		return nil
+	}
+	buf := make([]byte, size)
+	size, err = unix.Llistxattr(absPath, buf)
+	if err != nil || size <= 0 {
+		return nil
+	}
+	names := []string{}
+	// the names are NUL-terminated and packed one after another
+	for _, name := range bytes.Split(buf[:size], []byte{0}) {
+		if len(name) > 0 {
+			names = append(names, string(name))
+		}
+	}
+	return names
+}
+
+// getXattr reads the value of an extended attribute of a file, without following symlinks.
+func getXattr(absPath string, name string) ([]byte, error) {
+	size, err := unix.Lgetxattr(absPath, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	// the value can grow between the calls, in which case this fails with ERANGE like getfattr would
+	buf := make([]byte, size)
+	size, err = unix.Lgetxattr(absPath, name, buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:size], nil
+}
@@ -0,0 +1,54 @@
+//go:build linux || darwin
+
+package lsgo
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestListXattrs(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "file")
+	writeTestFile(t, filePath, "")
+	writeTestFile(t, filepath.Join(dir, "plain"), "")
+	if err := unix.Setxattr(filePath, "user.origin", []byte("https://example.com"), 0); err != nil {
+		t.Skip("the filesystem doesn't support extended attributes:", err)
+	}
+
+	listing := listTestDir(t, Options{XattrValues: true}, dir)
+	if len(listing.Errors) > 0 {
+		t.Fatal(listing.Errors)
+	}
+	entry := findEntry(t, listing, "file")
+	if !reflect.DeepEqual(entry.Xattrs, []string{"user.origin"}) {
+		t.Errorf("expected the user.origin attribute, got %q", entry.Xattrs)
+	}
+	if value := string(entry.XattrValues["user.origin"]); value != "https://example.com" {
+		t.Errorf("user.origin = %q", value)
+	}
+	if xattrs := findEntry(t, listing, "plain").Xattrs; len(xattrs) > 0 {
+		t.Errorf("expected no attributes on plain, got %q", xattrs)
+	}
+
+	// the attributes go on the lines beneath the name, and the permissions are marked with @
+	out := renderTestListing(t, RenderOptions{Perms: true, Xattrs: true, XattrValues: true}, listing)
+	lines := strings.Split(out, "\n")
+	for i, line := range lines {
+		if strings.HasSuffix(line, " file") {
+			if !strings.Contains(line, "@") {
+				t.Errorf("no @ in the permissions: %q", line)
+			}
+			if i+1 >= len(lines) || !strings.Contains(lines[i+1], "user.origin") ||
+				!strings.Contains(lines[i+1], "https://example.com") {
+				t.Errorf("the attribute isn't beneath the name in %q", out)
+			}
+			return
+		}
+	}
+	t.Errorf("file isn't in %q", out)
+}